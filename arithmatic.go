@@ -49,6 +49,9 @@ MultVec multiples the elements of each row of a [][]float64 by a []float64, modi
 the [][]float64 in place.
 */
 func MultVec(m [][]float64, v []float64) {
+	if debug {
+		check(checkOperand("MultVec()", m, v))
+	}
 	for i := range m {
 		for j := range v {
 			m[i][j] *= v[j]
@@ -61,6 +64,9 @@ MultMat multiplies element-wise a [][]float64 by another, modifying
 the first in place.
 */
 func MultMat(m, v [][]float64) {
+	if debug {
+		check(checkOperand("MultMat()", m, v))
+	}
 	for i := range m {
 		for j := range m[i] {
 			m[i][j] *= v[i][j]
@@ -115,6 +121,9 @@ AddVec increases the elements of each row of a [][]float64 by a []float64, modif
 the [][]float64 in place.
 */
 func AddVec(m [][]float64, v []float64) {
+	if debug {
+		check(checkOperand("AddVec()", m, v))
+	}
 	for i := range m {
 		for j := range v {
 			m[i][j] += v[j]
@@ -127,6 +136,9 @@ AddMat is an element-wise addition of a [][]float64 by another, modifying
 the first in place.
 */
 func AddMat(m, v [][]float64) {
+	if debug {
+		check(checkOperand("AddMat()", m, v))
+	}
 	for i := range m {
 		for j := range m[i] {
 			m[i][j] += v[i][j]
@@ -182,6 +194,9 @@ AddVec increases the elements of each row of a [][]float64 by a []float64, modif
 the [][]float64 in place.
 */
 func SubVec(m [][]float64, v []float64) {
+	if debug {
+		check(checkOperand("SubVec()", m, v))
+	}
 	for i := range m {
 		for j := range v {
 			m[i][j] -= v[j]
@@ -194,6 +209,9 @@ AddMat is an element-wise addition of a [][]float64 by another, modifying
 the first in place.
 */
 func SubMat(m, v [][]float64) {
+	if debug {
+		check(checkOperand("SubMat()", m, v))
+	}
 	for i := range m {
 		for j := range m[i] {
 			m[i][j] -= v[i][j]
@@ -249,6 +267,9 @@ AddVec increases the elements of each row of a [][]float64 by a []float64, modif
 the [][]float64 in place.
 */
 func DivVec(m [][]float64, v []float64) {
+	if debug {
+		check(checkOperand("DivVec()", m, v))
+	}
 	for i := range m {
 		for j := range v {
 			m[i][j] /= v[j]
@@ -261,6 +282,9 @@ AddMat is an element-wise addition of a [][]float64 by another, modifying
 the first in place.
 */
 func DivMat(m, v [][]float64) {
+	if debug {
+		check(checkOperand("DivMat()", m, v))
+	}
 	for i := range m {
 		for j := range m[i] {
 			m[i][j] /= v[i][j]
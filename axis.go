@@ -0,0 +1,130 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+SumAxis returns the sums of all rows or all columns of a [][]float64 in a single
+pass. The second argument must be either 0 to sum each row, in which case the
+returned []float64 has an entry for every row, or 1 to sum each column, in
+which case it has an entry for every column. For example:
+
+	m := matf64.New(3, 2)
+	matf64.Set(m, 1.0)
+	matf64.SumAxis(m, 0) // [2.0, 2.0, 2.0]
+	matf64.SumAxis(m, 1) // [3.0, 3.0]
+
+This follows the axis convention of Sum, where matf64.SumAxis(m, 1)[j] equals
+matf64.Sum(m, 1, j). The passed [][]float64 is assumed to be non-jagged, and is
+not mutated in this function.
+*/
+func SumAxis(m [][]float64, axis int) []float64 {
+	if debug {
+		check(checkRect("SumAxis()", m))
+	}
+	return reduceAxis("SumAxis()", m, axis, 0.0, func(acc, x float64) float64 {
+		return acc + x
+	})
+}
+
+/*
+ProdAxis returns the products of all rows or all columns of a [][]float64, with
+the axis chosen as in SumAxis.
+*/
+func ProdAxis(m [][]float64, axis int) []float64 {
+	if debug {
+		check(checkRect("ProdAxis()", m))
+	}
+	return reduceAxis("ProdAxis()", m, axis, 1.0, func(acc, x float64) float64 {
+		return acc * x
+	})
+}
+
+/*
+AvgAxis returns the averages of all rows or all columns of a [][]float64, with
+the axis chosen as in SumAxis.
+*/
+func AvgAxis(m [][]float64, axis int) []float64 {
+	if debug {
+		check(checkRect("AvgAxis()", m))
+	}
+	v := reduceAxis("AvgAxis()", m, axis, 0.0, func(acc, x float64) float64 {
+		return acc + x
+	})
+	n := len(m)
+	if axis == 0 {
+		n = 0
+		if len(m) > 0 {
+			n = len(m[0])
+		}
+	}
+	for i := range v {
+		v[i] /= float64(n)
+	}
+	return v
+}
+
+/*
+MinAxis returns the smallest value in each row or each column of a
+[][]float64, with the axis chosen as in SumAxis. If any value in a row or
+column is NaN, its minimum is NaN.
+*/
+func MinAxis(m [][]float64, axis int) []float64 {
+	if debug {
+		check(checkRect("MinAxis()", m))
+	}
+	return reduceAxis("MinAxis()", m, axis, math.Inf(1), math.Min)
+}
+
+/*
+MaxAxis returns the largest value in each row or each column of a
+[][]float64, with the axis chosen as in SumAxis. If any value in a row or
+column is NaN, its maximum is NaN.
+*/
+func MaxAxis(m [][]float64, axis int) []float64 {
+	if debug {
+		check(checkRect("MaxAxis()", m))
+	}
+	return reduceAxis("MaxAxis()", m, axis, math.Inf(-1), math.Max)
+}
+
+/*
+reduceAxis folds f over each row (axis 0) or each column (axis 1) of m,
+starting every row or column from init. The columns are all folded in the same
+pass over m, row by row. The name of the calling function is used in the panic
+message for an invalid axis.
+*/
+func reduceAxis(caller string, m [][]float64, axis int, init float64, f func(acc, x float64) float64) []float64 {
+	var v []float64
+	switch axis {
+	case 0:
+		v = make([]float64, len(m))
+		for i := range m {
+			acc := init
+			for j := range m[i] {
+				acc = f(acc, m[i][j])
+			}
+			v[i] = acc
+		}
+	case 1:
+		if len(m) == 0 {
+			return []float64{}
+		}
+		v = make([]float64, len(m[0]))
+		for j := range v {
+			v[j] = init
+		}
+		for i := range m {
+			for j := range v {
+				v[j] = f(v[j], m[i][j])
+			}
+		}
+	default:
+		s := "In matf64.%s the axis must be 0 for rows, or 1 for columns, but %d was passed."
+		s = fmt.Sprintf(s, caller, axis)
+		panic(s)
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+package matf64
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/*
+ErrNotPositiveDefinite is returned by Cholesky when the passed [][]float64 is
+not symmetric positive definite.
+*/
+var ErrNotPositiveDefinite = errors.New("matf64: matrix is not positive definite")
+
+/*
+Cholesky computes the Cholesky factorization of a symmetric positive definite
+[][]float64, returning the lower triangular l such that
+
+	m = Dot(l, T(l))
+
+Only the lower triangle of m, including the diagonal, is read, and the upper
+triangle is assumed to mirror it. ErrNotPositiveDefinite is returned if m is
+not positive definite. The original [][]float64 is not mutated in this function.
+*/
+func Cholesky(m [][]float64) ([][]float64, error) {
+	checkSquare("Cholesky()", m)
+	n := len(m)
+	l := New(n)
+	for j := 0; j < n; j++ {
+		d := m[j][j]
+		for k := 0; k < j; k++ {
+			d -= l[j][k] * l[j][k]
+		}
+		if d <= 0.0 || math.IsNaN(d) {
+			return nil, ErrNotPositiveDefinite
+		}
+		l[j][j] = math.Sqrt(d)
+		for i := j + 1; i < n; i++ {
+			s := m[i][j]
+			for k := 0; k < j; k++ {
+				s -= l[i][k] * l[j][k]
+			}
+			l[i][j] = s / l[j][j]
+		}
+	}
+	return l, nil
+}
+
+/*
+CholeskySolve finds x in the linear system a * x = b, where l is the Cholesky
+factor of a as returned by Cholesky. This allows many systems sharing the same
+symmetric positive definite [][]float64 to be solved with a single
+factorization, for example:
+
+	l, err := matf64.Cholesky(a)
+	x := matf64.CholeskySolve(l, b)
+
+The passed arguments are not mutated by this function.
+*/
+func CholeskySolve(l [][]float64, b []float64) []float64 {
+	if debug {
+		check(checkRect("CholeskySolve()", l))
+	}
+	if len(b) != len(l) {
+		s := "In matf64.%s the []float64 has %d entries, but the [][]float64 has %d rows."
+		s = fmt.Sprintf(s, "CholeskySolve()", len(b), len(l))
+		panic(s)
+	}
+	n := len(l)
+	x := make([]float64, n)
+	copy(x, b)
+	for i := 0; i < n; i++ {
+		for k := 0; k < i; k++ {
+			x[i] -= l[i][k] * x[k]
+		}
+		x[i] /= l[i][i]
+	}
+	for i := n - 1; i >= 0; i-- {
+		for k := i + 1; k < n; k++ {
+			x[i] -= l[k][i] * x[k]
+		}
+		x[i] /= l[i][i]
+	}
+	return x
+}
+
+/*
+CholeskyLogDet returns the natural logarithm of the determinant of a symmetric
+positive definite [][]float64, given its Cholesky factor l. Working with the
+logarithm avoids the overflow and underflow which the determinant of large
+covariance matrices is prone to.
+*/
+func CholeskyLogDet(l [][]float64) float64 {
+	logDet := 0.0
+	for i := range l {
+		logDet += math.Log(l[i][i])
+	}
+	return 2.0 * logDet
+}
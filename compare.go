@@ -0,0 +1,152 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+Tol describes how closely two float64s must agree to be considered equal by
+EqualApprox and Diff. Two float64s a and b are equal if they are identical, or
+if any of the following holds:
+
+	|a - b| <= Abs
+	|a - b| <= Rel * max(|a|, |b|)
+	at most ULP representable float64s lie between a and b
+
+The zero value of Tol only accepts identical values, as Equal does. Infinities
+are only equal to themselves, and NaN is only equal to NaN when NaNEqual is
+true. For example, a typical tolerance for comparing the result of a few
+arithmetic operations is
+
+	tol := matf64.Tol{Abs: 1e-12, Rel: 1e-9}
+*/
+type Tol struct {
+	Abs      float64
+	Rel      float64
+	ULP      uint64
+	NaNEqual bool
+}
+
+/*
+Equal checks to see if two float64s are equal within the tolerance.
+*/
+func (t Tol) Equal(a, b float64) bool {
+	if a == b {
+		return true
+	}
+	if math.IsNaN(a) || math.IsNaN(b) {
+		return t.NaNEqual && math.IsNaN(a) && math.IsNaN(b)
+	}
+	if math.IsInf(a, 0) || math.IsInf(b, 0) {
+		return false
+	}
+	d := math.Abs(a - b)
+	if d <= t.Abs || d <= t.Rel*math.Max(math.Abs(a), math.Abs(b)) {
+		return true
+	}
+	return t.ULP > 0 && ulpDist(a, b) <= t.ULP
+}
+
+/*
+ulpDist returns the number of representable float64s between a and b, neither
+of which may be NaN.
+*/
+func ulpDist(a, b float64) uint64 {
+	ordered := func(x float64) int64 {
+		i := int64(math.Float64bits(x))
+		if i < 0 {
+			i = math.MinInt64 - i
+		}
+		return i
+	}
+	ia, ib := ordered(a), ordered(b)
+	if ia < ib {
+		ia, ib = ib, ia
+	}
+	return uint64(ia) - uint64(ib)
+}
+
+/*
+EqualApprox checks to see if two [][]float64s are equal within a tolerance.
+That means that the two slices have the same number of rows, same number of
+columns, and the float64s at each set of indices are equal as defined by Tol.
+For example:
+
+	matf64.EqualApprox(m, n, matf64.Tol{Rel: 1e-12, NaNEqual: true})
+*/
+func EqualApprox(m, n [][]float64, tol Tol) bool {
+	if len(m) != len(n) {
+		return false
+	}
+	for i := range m {
+		if len(m[i]) != len(n[i]) {
+			return false
+		}
+	}
+	for i := range m {
+		for j := range m[i] {
+			if !tol.Equal(m[i][j], n[i][j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+/*
+Mismatch describes an entry in which two [][]float64s differ, as reported by
+Diff.
+*/
+type Mismatch struct {
+	Row, Col  int
+	Got, Want float64
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("(%d, %d): got %v, want %v", m.Row, m.Col, m.Got, m.Want)
+}
+
+/*
+Diff compares two [][]float64s entry by entry using a tolerance, and returns
+the mismatching entries in row major order, which makes for readable test
+failures:
+
+	for _, d := range matf64.Diff(got, want, tol) {
+		t.Errorf("%v", d)
+	}
+
+If the shapes of got and want differ, the entries which exist in only one of
+them are reported as well, with the missing value set to NaN. Diff returns nil
+if the [][]float64s are equal as defined by EqualApprox.
+*/
+func Diff(got, want [][]float64, tol Tol) []Mismatch {
+	var diffs []Mismatch
+	rows := len(got)
+	if len(want) > rows {
+		rows = len(want)
+	}
+	at := func(m [][]float64, i, j int) (float64, bool) {
+		if i < len(m) && j < len(m[i]) {
+			return m[i][j], true
+		}
+		return math.NaN(), false
+	}
+	for i := 0; i < rows; i++ {
+		cols := 0
+		if i < len(got) {
+			cols = len(got[i])
+		}
+		if i < len(want) && len(want[i]) > cols {
+			cols = len(want[i])
+		}
+		for j := 0; j < cols; j++ {
+			g, gok := at(got, i, j)
+			w, wok := at(want, i, j)
+			if !gok || !wok || !tol.Equal(g, w) {
+				diffs = append(diffs, Mismatch{Row: i, Col: j, Got: g, Want: w})
+			}
+		}
+	}
+	return diffs
+}
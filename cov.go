@@ -0,0 +1,163 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+CorrMethod selects the kind of correlation coefficient computed by Corr.
+*/
+type CorrMethod int
+
+const (
+	// Pearson is the linear correlation coefficient of the values.
+	Pearson CorrMethod = iota
+	// Spearman is the rank correlation coefficient, which is the Pearson
+	// coefficient of the ranks of the values, with ties given their average rank.
+	Spearman
+)
+
+/*
+Cov returns the sample covariance matrix of a [][]float64 whose rows are
+observations and whose columns are variables. The returned [][]float64 is
+square, with an entry for each pair of columns: the entry at row i, column j is
+the covariance of column i and column j of m, normalized by the number of rows
+minus one. For example:
+
+	m := [][]float64{{1.0, 2.0}, {2.0, 4.0}, {3.0, 6.0}}
+	matf64.Cov(m) // [[1.0, 2.0], [2.0, 4.0]]
+
+The passed [][]float64 is assumed to be non-jagged, and is not mutated in this
+function.
+*/
+func Cov(m [][]float64) [][]float64 {
+	if debug {
+		check(checkRect("Cov()", m))
+	}
+	c := Clone(m)
+	SubVec(c, AvgAxis(m, 1))
+	cov := Dot(T(c), c)
+	DivScalar(cov, float64(len(m)-1))
+	return cov
+}
+
+/*
+CovWeighted returns the weighted sample covariance matrix of a [][]float64
+whose rows are observations and whose columns are variables, where w holds a
+non-negative weight for each row. The weighted means are used for centering,
+and the result is normalized by
+
+	V1 - V2 / V1
+
+where V1 is the sum of the weights and V2 is the sum of their squares, which
+makes the estimate unbiased for reliability weights. With all weights equal,
+CovWeighted is the same as Cov. The passed arguments are not mutated by this
+function.
+*/
+func CovWeighted(m [][]float64, w []float64) [][]float64 {
+	if debug {
+		check(checkRect("CovWeighted()", m))
+	}
+	if len(w) != len(m) {
+		s := "In matf64.%s the []float64 has %d weights, but the [][]float64 has %d rows."
+		s = fmt.Sprintf(s, "CovWeighted()", len(w), len(m))
+		panic(s)
+	}
+	v1, v2 := 0.0, 0.0
+	for i := range w {
+		v1 += w[i]
+		v2 += w[i] * w[i]
+	}
+	c := Clone(m)
+	mean := make([]float64, len(m[0]))
+	for i := range m {
+		for j := range m[i] {
+			mean[j] += w[i] * m[i][j]
+		}
+	}
+	for j := range mean {
+		mean[j] /= v1
+	}
+	SubVec(c, mean)
+	wc := Clone(c)
+	for i := range wc {
+		for j := range wc[i] {
+			wc[i][j] *= w[i]
+		}
+	}
+	cov := Dot(T(wc), c)
+	DivScalar(cov, v1-v2/v1)
+	return cov
+}
+
+/*
+Corr returns the correlation matrix of a [][]float64 whose rows are
+observations and whose columns are variables, using either the Pearson or
+Spearman coefficient. The entry at row i, column j is the correlation of
+column i and column j of m, and the diagonal is 1.0. A column which is
+constant has no defined correlation, and its entries are NaN. The passed
+[][]float64 is assumed to be non-jagged, and is not mutated in this function.
+*/
+func Corr(m [][]float64, method CorrMethod) [][]float64 {
+	if debug {
+		check(checkRect("Corr()", m))
+	}
+	var cov [][]float64
+	switch method {
+	case Pearson:
+		cov = Cov(m)
+	case Spearman:
+		ranks := New(len(m), len(m[0]))
+		for j := range m[0] {
+			r := rank(Col(m, j))
+			for i := range ranks {
+				ranks[i][j] = r[i]
+			}
+		}
+		cov = Cov(ranks)
+	default:
+		s := "In matf64.%s received unknown correlation method %d."
+		s = fmt.Sprintf(s, "Corr()", method)
+		panic(s)
+	}
+	std := make([]float64, len(cov))
+	for i := range cov {
+		std[i] = math.Sqrt(cov[i][i])
+	}
+	for i := range cov {
+		for j := range cov[i] {
+			cov[i][j] /= std[i] * std[j]
+		}
+		if !math.IsNaN(cov[i][i]) {
+			cov[i][i] = 1.0
+		}
+	}
+	return cov
+}
+
+/*
+rank returns the 1-based ranks of the values in v, where tied values all get
+the average of the ranks they span.
+*/
+func rank(v []float64) []float64 {
+	idx := make([]int, len(v))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return v[idx[a]] < v[idx[b]] })
+	r := make([]float64, len(v))
+	for i := 0; i < len(idx); {
+		j := i + 1
+		for j < len(idx) && v[idx[j]] == v[idx[i]] {
+			j++
+		}
+		avg := float64(i+j+1) / 2.0
+		for k := i; k < j; k++ {
+			r[idx[k]] = avg
+		}
+		i = j
+	}
+	return r
+}
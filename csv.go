@@ -0,0 +1,154 @@
+package matf64
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+CSVOptions configures ReadCSV and WriteCSV. The zero value reads and writes
+plain comma separated values, without a header or comments, and treats empty
+cells as errors.
+*/
+type CSVOptions struct {
+	// Comma is the field delimiter, such as '\t' for TSV. It defaults to ','.
+	Comma rune
+	// Comment, if not zero, marks lines to be skipped when it is their first
+	// character.
+	Comment rune
+	// Header marks the first record as a row of column names rather than values.
+	Header bool
+	// EmptyAsNaN reads empty cells as NaN, and writes NaN as empty cells.
+	EmptyAsNaN bool
+}
+
+/*
+CSVError describes a cell which ReadCSV could not parse as a float64. Line and
+Column are the 1-based position at which the cell starts in the input, and
+Field is its 0-based index within its record.
+*/
+type CSVError struct {
+	Line, Column int
+	Field        int
+	Value        string
+	Err          error
+}
+
+func (e *CSVError) Error() string {
+	s := "matf64.ReadCSV(): line %d, column %d: cannot parse %q as a float64: %v"
+	return fmt.Sprintf(s, e.Line, e.Column, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying parsing error.
+func (e *CSVError) Unwrap() error { return e.Err }
+
+/*
+ReadCSV reads a [][]float64 from comma separated values, with one row per
+record. For example, reading a TSV file with a header line:
+
+	m, header, err := matf64.ReadCSV(f, matf64.CSVOptions{Comma: '\t', Header: true})
+
+The header is nil unless opts.Header is set. Every record must have the same
+number of fields. Values are parsed with strconv.ParseFloat after trimming
+surrounding white space, so NaN, Inf and scientific notation are accepted. A
+CSVError pinpointing the offending cell is returned for values which can not be
+parsed, while malformed CSV is reported with the *csv.ParseError of the
+encoding/csv package.
+*/
+func ReadCSV(r io.Reader, opts CSVOptions) (m [][]float64, header []string, err error) {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+	cr.ReuseRecord = true
+	first := true
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if first && opts.Header {
+			header = append([]string(nil), rec...)
+			first = false
+			continue
+		}
+		first = false
+		row := make([]float64, len(rec))
+		for j, field := range rec {
+			field = strings.TrimSpace(field)
+			if field == "" && opts.EmptyAsNaN {
+				row[j] = math.NaN()
+				continue
+			}
+			row[j], err = strconv.ParseFloat(field, 64)
+			if err != nil {
+				line, col := cr.FieldPos(j)
+				if ne, ok := err.(*strconv.NumError); ok {
+					err = ne.Err
+				}
+				return nil, nil, &CSVError{Line: line, Column: col, Field: j, Value: rec[j], Err: err}
+			}
+		}
+		m = append(m, row)
+	}
+	return m, header, nil
+}
+
+/*
+WriteCSV writes a [][]float64 as comma separated values, with one record per
+row, preceded by the header if it is not nil. Values are written in the
+shortest form which reads back to the exact same float64. For example:
+
+	err := matf64.WriteCSV(f, m, []string{"x", "y"}, matf64.CSVOptions{})
+
+If opts.EmptyAsNaN is set, NaN values are written as empty cells, so that the
+output can be read back with the same options. An empty cell which is alone on
+its line is written as "", as a blank line would be skipped when reading.
+*/
+func WriteCSV(w io.Writer, m [][]float64, header []string, opts CSVOptions) error {
+	cw := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		cw.Comma = opts.Comma
+	}
+	if header != nil {
+		if err := cw.Write(header); err != nil {
+			return err
+		}
+	}
+	var rec []string
+	for i := range m {
+		rec = rec[:0]
+		for _, x := range m[i] {
+			if math.IsNaN(x) && opts.EmptyAsNaN {
+				rec = append(rec, "")
+				continue
+			}
+			rec = append(rec, strconv.FormatFloat(x, 'g', -1, 64))
+		}
+		if len(rec) == 1 && rec[0] == "" {
+			// encoding/csv would write a blank line, which readers skip, so the
+			// empty field is quoted to keep the row.
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "\"\"\n"); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
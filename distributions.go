@@ -0,0 +1,330 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+RandNormal creates a x by y [][]float64 with entries drawn from the normal
+distribution with the passed mean and standard deviation, using the global
+source of the math/rand package. For example:
+
+	m := matf64.RandNormal(3, 4, 0.0, 1.0)
+
+gives a 3 by 4 [][]float64 of standard normal entries. The RandNormal method of
+a Rand created with NewRand gives reproducible values.
+*/
+func RandNormal(x, y int, mean, std float64) [][]float64 {
+	return global.RandNormal(x, y, mean, std)
+}
+
+/*
+RandLogNormal creates a x by y [][]float64 with entries drawn from the
+log-normal distribution, whose logarithm is normally distributed with mean mu
+and standard deviation sigma, using the global source of the math/rand
+package.
+*/
+func RandLogNormal(x, y int, mu, sigma float64) [][]float64 {
+	return global.RandLogNormal(x, y, mu, sigma)
+}
+
+/*
+RandExp creates a x by y [][]float64 with entries drawn from the exponential
+distribution with the passed rate, which has a mean of 1 / rate, using the
+global source of the math/rand package.
+*/
+func RandExp(x, y int, rate float64) [][]float64 {
+	return global.RandExp(x, y, rate)
+}
+
+/*
+RandPoisson creates a x by y [][]float64 with entries drawn from the Poisson
+distribution with mean lambda, using the global source of the math/rand
+package. The entries are whole numbers.
+*/
+func RandPoisson(x, y int, lambda float64) [][]float64 {
+	return global.RandPoisson(x, y, lambda)
+}
+
+/*
+RandBernoulli creates a x by y [][]float64 whose entries are 1.0 with
+probability p, and 0.0 otherwise, using the global source of the math/rand
+package.
+*/
+func RandBernoulli(x, y int, p float64) [][]float64 {
+	return global.RandBernoulli(x, y, p)
+}
+
+/*
+RandTruncNormal creates a x by y [][]float64 with entries drawn from the
+normal distribution with the passed mean and standard deviation, truncated to
+the range [lo, hi], using the global source of the math/rand package. Either
+bound may be infinite.
+*/
+func RandTruncNormal(x, y int, mean, std, lo, hi float64) [][]float64 {
+	return global.RandTruncNormal(x, y, mean, std, lo, hi)
+}
+
+/*
+RandMultiNormal draws n samples from the multivariate normal distribution with
+the passed mean and covariance [][]float64, using the global source of the
+math/rand package. Each sample is a row of the returned [][]float64.
+*/
+func RandMultiNormal(n int, mean []float64, cov [][]float64) ([][]float64, error) {
+	return global.RandMultiNormal(n, mean, cov)
+}
+
+/*
+RandNormal creates a x by y [][]float64 with entries drawn from r, following
+the normal distribution with the passed mean and standard deviation. It panics
+if the standard deviation is negative.
+*/
+func (r *Rand) RandNormal(x, y int, mean, std float64) [][]float64 {
+	if !(std >= 0.0) {
+		s := "In matf64.%s the standard deviation must not be negative, but %v was passed."
+		panic(fmt.Sprintf(s, "RandNormal()", std))
+	}
+	return r.fill("RandNormal()", x, y, func() float64 {
+		return mean + std*r.normFloat64()
+	})
+}
+
+/*
+RandLogNormal creates a x by y [][]float64 with entries drawn from r,
+following the log-normal distribution whose logarithm has mean mu and standard
+deviation sigma. It panics if sigma is negative.
+*/
+func (r *Rand) RandLogNormal(x, y int, mu, sigma float64) [][]float64 {
+	if !(sigma >= 0.0) {
+		s := "In matf64.%s sigma must not be negative, but %v was passed."
+		panic(fmt.Sprintf(s, "RandLogNormal()", sigma))
+	}
+	return r.fill("RandLogNormal()", x, y, func() float64 {
+		return math.Exp(mu + sigma*r.normFloat64())
+	})
+}
+
+/*
+RandExp creates a x by y [][]float64 with entries drawn from r, following the
+exponential distribution with the passed rate. It panics if the rate is not
+positive.
+*/
+func (r *Rand) RandExp(x, y int, rate float64) [][]float64 {
+	if !(rate > 0.0) {
+		s := "In matf64.%s the rate must be positive, but %v was passed."
+		panic(fmt.Sprintf(s, "RandExp()", rate))
+	}
+	return r.fill("RandExp()", x, y, func() float64 {
+		return r.expFloat64() / rate
+	})
+}
+
+/*
+RandPoisson creates a x by y [][]float64 with entries drawn from r, following
+the Poisson distribution with mean lambda. Small means use the multiplication
+method of Knuth, and means of 10 or more the transformed rejection method
+(PTRS) of Hörmann, whose cost does not grow with the mean. It panics if lambda
+is negative.
+*/
+func (r *Rand) RandPoisson(x, y int, lambda float64) [][]float64 {
+	if !(lambda >= 0.0) || math.IsInf(lambda, 1) {
+		s := "In matf64.%s lambda must be finite and not negative, but %v was passed."
+		panic(fmt.Sprintf(s, "RandPoisson()", lambda))
+	}
+	if lambda < 10.0 {
+		limit := math.Exp(-lambda)
+		return r.fill("RandPoisson()", x, y, func() float64 {
+			k, p := 0.0, r.float64()
+			for p > limit {
+				k++
+				p *= r.float64()
+			}
+			return k
+		})
+	}
+	slam := math.Sqrt(lambda)
+	loglam := math.Log(lambda)
+	b := 0.931 + 2.53*slam
+	a := -0.059 + 0.02483*b
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2.0)
+	return r.fill("RandPoisson()", x, y, func() float64 {
+		for {
+			u := r.float64() - 0.5
+			v := r.float64()
+			us := 0.5 - math.Abs(u)
+			k := math.Floor((2.0*a/us+b)*u + lambda + 0.43)
+			if us >= 0.07 && v <= vr {
+				return k
+			}
+			if k < 0.0 || (us < 0.013 && v > us) {
+				continue
+			}
+			lg, _ := math.Lgamma(k + 1.0)
+			if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <= -lambda+k*loglam-lg {
+				return k
+			}
+		}
+	})
+}
+
+/*
+RandBernoulli creates a x by y [][]float64 whose entries are drawn from r, and
+are 1.0 with probability p, and 0.0 otherwise. It panics if p is not between 0
+and 1, inclusive.
+*/
+func (r *Rand) RandBernoulli(x, y int, p float64) [][]float64 {
+	if !(p >= 0.0 && p <= 1.0) {
+		s := "In matf64.%s the probability must be between 0 and 1, but %v was passed."
+		panic(fmt.Sprintf(s, "RandBernoulli()", p))
+	}
+	return r.fill("RandBernoulli()", x, y, func() float64 {
+		if r.float64() < p {
+			return 1.0
+		}
+		return 0.0
+	})
+}
+
+/*
+RandTruncNormal creates a x by y [][]float64 with entries drawn from r,
+following the normal distribution with the passed mean and standard deviation,
+truncated to the range [lo, hi]. The entries are drawn with the rejection
+samplers of Robert (1995), which stay efficient for ranges far in the tails of
+the distribution, where drawing normal values until one falls in the range
+would take practically forever. It panics if the standard deviation is not
+positive, or if lo is not smaller than hi.
+*/
+func (r *Rand) RandTruncNormal(x, y int, mean, std, lo, hi float64) [][]float64 {
+	if !(std > 0.0) || !(lo < hi) {
+		s := "In matf64.%s expected a positive standard deviation and lo < hi, but received\n"
+		s += "std = %v, lo = %v, hi = %v."
+		panic(fmt.Sprintf(s, "RandTruncNormal()", std, lo, hi))
+	}
+	a, b := (lo-mean)/std, (hi-mean)/std
+	return r.fill("RandTruncNormal()", x, y, func() float64 {
+		// Rounding may carry the scaled value just past a bound.
+		v := mean + std*r.truncNorm(a, b)
+		if v < lo {
+			return lo
+		}
+		if v > hi {
+			return hi
+		}
+		return v
+	})
+}
+
+/*
+truncNorm returns a standard normal number truncated to [a, b]. The caller must
+hold r.mu.
+*/
+func (r *Rand) truncNorm(a, b float64) float64 {
+	switch {
+	case b <= 0.0:
+		return -r.truncNorm(-b, -a)
+	case a < 0.0 && b-a >= 1.0:
+		// A wide range around the mean, which holds a large share of normal values.
+		for {
+			z := r.normFloat64()
+			if z >= a && z <= b {
+				return z
+			}
+		}
+	case a < 0.0:
+		// A narrow range around the mean, where the density is nearly flat.
+		for {
+			z := a + (b-a)*r.float64()
+			if r.float64() <= math.Exp(-z*z/2.0) {
+				return z
+			}
+		}
+	}
+	// A range in the upper tail, with a >= 0. The exponential proposal of
+	// Robert, shifted to a, fits the tail best with the rate alpha.
+	alpha := (a + math.Sqrt(a*a+4.0)) / 2.0
+	if b-a < 1.0/alpha {
+		for {
+			z := a + (b-a)*r.float64()
+			if r.float64() <= math.Exp((a*a-z*z)/2.0) {
+				return z
+			}
+		}
+	}
+	for {
+		z := a + r.expFloat64()/alpha
+		if z > b {
+			continue
+		}
+		d := z - alpha
+		if r.float64() <= math.Exp(-d*d/2.0) {
+			return z
+		}
+	}
+}
+
+/*
+RandMultiNormal draws n samples from r, following the multivariate normal
+distribution with the passed mean and covariance [][]float64. Each sample is a
+row of the returned [][]float64. The samples are computed as mean + L z, where
+L is the Cholesky factor of the covariance and z holds standard normal numbers,
+so ErrNotPositiveDefinite is returned if the covariance is not positive
+definite. For example:
+
+	r := matf64.NewRand(1)
+	cov := [][]float64{{1.0, 0.8}, {0.8, 1.0}}
+	m, err := r.RandMultiNormal(1000, []float64{0.0, 0.0}, cov)
+
+draws 1000 samples of two strongly correlated variables.
+*/
+func (r *Rand) RandMultiNormal(n int, mean []float64, cov [][]float64) ([][]float64, error) {
+	if debug {
+		check(checkDims("RandMultiNormal()", n))
+	}
+	if len(cov) != len(mean) {
+		s := "In matf64.%s the mean has %d entries, but the covariance has %d rows."
+		panic(fmt.Sprintf(s, "RandMultiNormal()", len(mean), len(cov)))
+	}
+	l, err := Cholesky(cov)
+	if err != nil {
+		return nil, err
+	}
+	d := len(mean)
+	m := New(n, d)
+	z := make([]float64, d)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range m {
+		for j := range z {
+			z[j] = r.normFloat64()
+		}
+		for j := 0; j < d; j++ {
+			sum := mean[j]
+			for k := 0; k <= j; k++ {
+				sum += l[j][k] * z[k]
+			}
+			m[i][j] = sum
+		}
+	}
+	return m, nil
+}
+
+/*
+fill creates a x by y [][]float64 whose entries are set by calling f in row
+major order, while holding r.mu.
+*/
+func (r *Rand) fill(caller string, x, y int, f func() float64) [][]float64 {
+	if debug {
+		check(checkDims(caller, x, y))
+	}
+	m := New(x, y)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = f()
+		}
+	}
+	return m
+}
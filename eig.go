@@ -0,0 +1,557 @@
+package matf64
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// maxQRIter is the number of shifted QR steps allowed for isolating each eigenvalue.
+const maxQRIter = 100
+
+/*
+Eig computes the eigenvalues and right eigenvectors of a square [][]float64,
+which need not be symmetric. The [][]float64 is first reduced to upper
+Hessenberg form by orthogonal similarity transformations, and then to real
+Schur form with the shifted double QR algorithm. The i-th column of vecs is the
+eigenvector belonging to vals[i], normalized to unit length, so that
+
+	m * vecs[:, i] = vals[i] * vecs[:, i]
+
+Complex eigenvalues of a real [][]float64 come in conjugate pairs, and are
+returned next to each other with the positive imaginary part first. The
+eigenvalues are not sorted. ErrNoConvergence is returned if the QR iteration
+fails to isolate an eigenvalue. For symmetric input, EigSym is both faster and
+more accurate. The original [][]float64 is not mutated in this function.
+*/
+func Eig(m [][]float64) (vals []complex128, vecs [][]complex128, err error) {
+	checkSquare("Eig()", m)
+	h, v, d, e, err := schur(m, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := len(m)
+	vals = make([]complex128, n)
+	vecs = make([][]complex128, n)
+	for i := range vecs {
+		vecs[i] = make([]complex128, n)
+	}
+	if n == 0 {
+		return vals, vecs, nil
+	}
+	schurVectors(h, v, d, e)
+	for j := 0; j < n; j++ {
+		vals[j] = complex(d[j], e[j])
+		switch {
+		case e[j] == 0.0:
+			for i := range vecs {
+				vecs[i][j] = complex(v[i][j], 0.0)
+			}
+		case e[j] > 0.0:
+			for i := range vecs {
+				vecs[i][j] = complex(v[i][j], v[i][j+1])
+				vecs[i][j+1] = complex(v[i][j], -v[i][j+1])
+			}
+		}
+	}
+	for j := 0; j < n; j++ {
+		norm := 0.0
+		for i := range vecs {
+			norm = math.Hypot(norm, cmplx.Abs(vecs[i][j]))
+		}
+		if norm == 0.0 {
+			continue
+		}
+		for i := range vecs {
+			vecs[i][j] /= complex(norm, 0.0)
+		}
+	}
+	return vals, vecs, nil
+}
+
+/*
+EigVals computes the eigenvalues of a square [][]float64 in the same way and
+order as Eig, but skips the work needed for the eigenvectors.
+*/
+func EigVals(m [][]float64) ([]complex128, error) {
+	checkSquare("EigVals()", m)
+	_, _, d, e, err := schur(m, false)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]complex128, len(m))
+	for i := range vals {
+		vals[i] = complex(d[i], e[i])
+	}
+	return vals, nil
+}
+
+/*
+schur reduces a copy of m to real Schur form h, returning the real and
+imaginary parts of the eigenvalues in d and e. If vectors is true, the
+orthogonal transformations are accumulated in v. This follows the orthes and
+hqr2 routines of EISPACK, by way of the public domain JAMA library.
+*/
+func schur(m [][]float64, vectors bool) (h, v [][]float64, d, e []float64, err error) {
+	nn := len(m)
+	h = Clone(m)
+	d = make([]float64, nn)
+	e = make([]float64, nn)
+	if vectors {
+		v = hessenberg(h, true)
+	} else {
+		hessenberg(h, false)
+	}
+	high := nn - 1
+	n := nn - 1
+	exshift := 0.0
+	var p, q, r, s, z, w, x, y float64
+
+	norm := 0.0
+	for i := 0; i < nn; i++ {
+		for j := i - 1; j < nn; j++ {
+			if j >= 0 {
+				norm += math.Abs(h[i][j])
+			}
+		}
+	}
+
+	iter := 0
+	for n >= 0 {
+		// Look for a single small sub-diagonal element.
+		l := n
+		for l > 0 {
+			s = math.Abs(h[l-1][l-1]) + math.Abs(h[l][l])
+			if s == 0.0 {
+				s = norm
+			}
+			if math.Abs(h[l][l-1]) < eps*s {
+				break
+			}
+			l--
+		}
+
+		switch {
+		case l == n:
+			// One root found.
+			h[n][n] += exshift
+			d[n] = h[n][n]
+			e[n] = 0.0
+			n--
+			iter = 0
+		case l == n-1:
+			// Two roots found.
+			w = h[n][n-1] * h[n-1][n]
+			p = (h[n-1][n-1] - h[n][n]) / 2.0
+			q = p*p + w
+			z = math.Sqrt(math.Abs(q))
+			h[n][n] += exshift
+			h[n-1][n-1] += exshift
+			x = h[n][n]
+			if q >= 0 {
+				// Real pair.
+				if p >= 0 {
+					z = p + z
+				} else {
+					z = p - z
+				}
+				d[n-1] = x + z
+				d[n] = d[n-1]
+				if z != 0.0 {
+					d[n] = x - w/z
+				}
+				e[n-1] = 0.0
+				e[n] = 0.0
+				x = h[n][n-1]
+				s = math.Abs(x) + math.Abs(z)
+				p = x / s
+				q = z / s
+				r = math.Sqrt(p*p + q*q)
+				p /= r
+				q /= r
+				for j := n - 1; j < nn; j++ {
+					z = h[n-1][j]
+					h[n-1][j] = q*z + p*h[n][j]
+					h[n][j] = q*h[n][j] - p*z
+				}
+				for i := 0; i <= n; i++ {
+					z = h[i][n-1]
+					h[i][n-1] = q*z + p*h[i][n]
+					h[i][n] = q*h[i][n] - p*z
+				}
+				if vectors {
+					for i := 0; i <= high; i++ {
+						z = v[i][n-1]
+						v[i][n-1] = q*z + p*v[i][n]
+						v[i][n] = q*v[i][n] - p*z
+					}
+				}
+			} else {
+				// Complex pair.
+				d[n-1] = x + p
+				d[n] = x + p
+				e[n-1] = z
+				e[n] = -z
+			}
+			n -= 2
+			iter = 0
+		default:
+			// No convergence yet, so form the shift.
+			x = h[n][n]
+			y = 0.0
+			w = 0.0
+			if l < n {
+				y = h[n-1][n-1]
+				w = h[n][n-1] * h[n-1][n]
+			}
+			// Wilkinson's original ad hoc shift.
+			if iter == 10 {
+				exshift += x
+				for i := 0; i <= n; i++ {
+					h[i][i] -= x
+				}
+				s = math.Abs(h[n][n-1]) + math.Abs(h[n-1][n-2])
+				x = 0.75 * s
+				y = x
+				w = -0.4375 * s * s
+			}
+			// MATLAB's ad hoc shift.
+			if iter == 30 {
+				s = (y - x) / 2.0
+				s = s*s + w
+				if s > 0 {
+					s = math.Sqrt(s)
+					if y < x {
+						s = -s
+					}
+					s = x - w/((y-x)/2.0+s)
+					for i := 0; i <= n; i++ {
+						h[i][i] -= s
+					}
+					exshift += s
+					x = 0.964
+					y = x
+					w = x
+				}
+			}
+			iter++
+			if iter > maxQRIter {
+				return nil, nil, nil, nil, ErrNoConvergence
+			}
+
+			// Look for two consecutive small sub-diagonal elements.
+			mm := n - 2
+			for mm >= l {
+				z = h[mm][mm]
+				r = x - z
+				s = y - z
+				p = (r*s-w)/h[mm+1][mm] + h[mm][mm+1]
+				q = h[mm+1][mm+1] - z - r - s
+				r = h[mm+2][mm+1]
+				s = math.Abs(p) + math.Abs(q) + math.Abs(r)
+				p /= s
+				q /= s
+				r /= s
+				if mm == l {
+					break
+				}
+				if math.Abs(h[mm][mm-1])*(math.Abs(q)+math.Abs(r)) <
+					eps*(math.Abs(p)*(math.Abs(h[mm-1][mm-1])+math.Abs(z)+math.Abs(h[mm+1][mm+1]))) {
+					break
+				}
+				mm--
+			}
+			for i := mm + 2; i <= n; i++ {
+				h[i][i-2] = 0.0
+				if i > mm+2 {
+					h[i][i-3] = 0.0
+				}
+			}
+
+			// Double QR step involving rows l:n and columns mm:n.
+			for k := mm; k <= n-1; k++ {
+				notlast := k != n-1
+				if k != mm {
+					p = h[k][k-1]
+					q = h[k+1][k-1]
+					r = 0.0
+					if notlast {
+						r = h[k+2][k-1]
+					}
+					x = math.Abs(p) + math.Abs(q) + math.Abs(r)
+					if x == 0.0 {
+						continue
+					}
+					p /= x
+					q /= x
+					r /= x
+				}
+				s = math.Sqrt(p*p + q*q + r*r)
+				if p < 0 {
+					s = -s
+				}
+				if s == 0 {
+					continue
+				}
+				if k != mm {
+					h[k][k-1] = -s * x
+				} else if l != mm {
+					h[k][k-1] = -h[k][k-1]
+				}
+				p += s
+				x = p / s
+				y = q / s
+				z = r / s
+				q /= p
+				r /= p
+				for j := k; j < nn; j++ {
+					p = h[k][j] + q*h[k+1][j]
+					if notlast {
+						p += r * h[k+2][j]
+						h[k+2][j] -= p * z
+					}
+					h[k][j] -= p * x
+					h[k+1][j] -= p * y
+				}
+				top := k + 3
+				if n < top {
+					top = n
+				}
+				for i := 0; i <= top; i++ {
+					p = x*h[i][k] + y*h[i][k+1]
+					if notlast {
+						p += z * h[i][k+2]
+						h[i][k+2] -= p * r
+					}
+					h[i][k] -= p
+					h[i][k+1] -= p * q
+				}
+				if vectors {
+					for i := 0; i <= high; i++ {
+						p = x*v[i][k] + y*v[i][k+1]
+						if notlast {
+							p += z * v[i][k+2]
+							v[i][k+2] -= p * r
+						}
+						v[i][k] -= p
+						v[i][k+1] -= p * q
+					}
+				}
+			}
+		}
+	}
+	return h, v, d, e, nil
+}
+
+/*
+hessenberg reduces h to upper Hessenberg form in place using Householder
+similarity transformations. If accumulate is true, the product of the
+transformations is returned.
+*/
+func hessenberg(h [][]float64, accumulate bool) [][]float64 {
+	n := len(h)
+	high := n - 1
+	ort := make([]float64, n)
+	for m := 1; m <= high-1; m++ {
+		scale := 0.0
+		for i := m; i <= high; i++ {
+			scale += math.Abs(h[i][m-1])
+		}
+		if scale == 0.0 {
+			continue
+		}
+		g, sum := 0.0, 0.0
+		for i := high; i >= m; i-- {
+			ort[i] = h[i][m-1] / scale
+			sum += ort[i] * ort[i]
+		}
+		g = math.Sqrt(sum)
+		if ort[m] > 0 {
+			g = -g
+		}
+		sum -= ort[m] * g
+		ort[m] -= g
+		for j := m; j < n; j++ {
+			f := 0.0
+			for i := high; i >= m; i-- {
+				f += ort[i] * h[i][j]
+			}
+			f /= sum
+			for i := m; i <= high; i++ {
+				h[i][j] -= f * ort[i]
+			}
+		}
+		for i := 0; i <= high; i++ {
+			f := 0.0
+			for j := high; j >= m; j-- {
+				f += ort[j] * h[i][j]
+			}
+			f /= sum
+			for j := m; j <= high; j++ {
+				h[i][j] -= f * ort[j]
+			}
+		}
+		ort[m] *= scale
+		h[m][m-1] = scale * g
+	}
+	if !accumulate {
+		return nil
+	}
+	v := I(n)
+	for m := high - 1; m >= 1; m-- {
+		if h[m][m-1] == 0.0 {
+			continue
+		}
+		for i := m + 1; i <= high; i++ {
+			ort[i] = h[i][m-1]
+		}
+		for j := m; j <= high; j++ {
+			g := 0.0
+			for i := m; i <= high; i++ {
+				g += ort[i] * v[i][j]
+			}
+			// Double division avoids possible underflow.
+			g = (g / ort[m]) / h[m][m-1]
+			for i := m; i <= high; i++ {
+				v[i][j] += g * ort[i]
+			}
+		}
+	}
+	return v
+}
+
+/*
+schurVectors back substitutes the real Schur form h to find its eigenvectors,
+and transforms them with v into the eigenvectors of the original [][]float64,
+which are stored in v. Real eigenvectors occupy a single column, while complex
+pairs store the real and imaginary parts in two adjacent columns.
+*/
+func schurVectors(h, v [][]float64, d, e []float64) {
+	nn := len(h)
+	norm := 0.0
+	for i := 0; i < nn; i++ {
+		for j := i - 1; j < nn; j++ {
+			if j >= 0 {
+				norm += math.Abs(h[i][j])
+			}
+		}
+	}
+	if norm == 0.0 {
+		return
+	}
+	var p, q, r, s, t, w, x, y, z float64
+	cdiv := func(xr, xi, yr, yi float64) (float64, float64) {
+		c := complex(xr, xi) / complex(yr, yi)
+		return real(c), imag(c)
+	}
+	for n := nn - 1; n >= 0; n-- {
+		p = d[n]
+		q = e[n]
+		if q == 0 {
+			// Real vector.
+			l := n
+			h[n][n] = 1.0
+			for i := n - 1; i >= 0; i-- {
+				w = h[i][i] - p
+				r = 0.0
+				for j := l; j <= n; j++ {
+					r += h[i][j] * h[j][n]
+				}
+				if e[i] < 0.0 {
+					z = w
+					s = r
+					continue
+				}
+				l = i
+				if e[i] == 0.0 {
+					if w != 0.0 {
+						h[i][n] = -r / w
+					} else {
+						h[i][n] = -r / (eps * norm)
+					}
+				} else {
+					x = h[i][i+1]
+					y = h[i+1][i]
+					q = (d[i]-p)*(d[i]-p) + e[i]*e[i]
+					t = (x*s - z*r) / q
+					h[i][n] = t
+					if math.Abs(x) > math.Abs(z) {
+						h[i+1][n] = (-r - w*t) / x
+					} else {
+						h[i+1][n] = (-s - y*t) / z
+					}
+				}
+				// Overflow control.
+				t = math.Abs(h[i][n])
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n] /= t
+					}
+				}
+			}
+		} else if q < 0 {
+			// Complex vector.
+			l := n - 1
+			// The last vector component is imaginary, so the matrix is triangular.
+			if math.Abs(h[n][n-1]) > math.Abs(h[n-1][n]) {
+				h[n-1][n-1] = q / h[n][n-1]
+				h[n-1][n] = -(h[n][n] - p) / h[n][n-1]
+			} else {
+				h[n-1][n-1], h[n-1][n] = cdiv(0.0, -h[n-1][n], h[n-1][n-1]-p, q)
+			}
+			h[n][n-1] = 0.0
+			h[n][n] = 1.0
+			for i := n - 2; i >= 0; i-- {
+				ra, sa := 0.0, 0.0
+				for j := l; j <= n; j++ {
+					ra += h[i][j] * h[j][n-1]
+					sa += h[i][j] * h[j][n]
+				}
+				w = h[i][i] - p
+				if e[i] < 0.0 {
+					z = w
+					r = ra
+					s = sa
+					continue
+				}
+				l = i
+				if e[i] == 0 {
+					h[i][n-1], h[i][n] = cdiv(-ra, -sa, w, q)
+				} else {
+					// Solve the complex equations.
+					x = h[i][i+1]
+					y = h[i+1][i]
+					vr := (d[i]-p)*(d[i]-p) + e[i]*e[i] - q*q
+					vi := (d[i] - p) * 2.0 * q
+					if vr == 0.0 && vi == 0.0 {
+						vr = eps * norm * (math.Abs(w) + math.Abs(q) + math.Abs(x) + math.Abs(y) + math.Abs(z))
+					}
+					h[i][n-1], h[i][n] = cdiv(x*r-z*ra+q*sa, x*s-z*sa-q*ra, vr, vi)
+					if math.Abs(x) > (math.Abs(z) + math.Abs(q)) {
+						h[i+1][n-1] = (-ra - w*h[i][n-1] + q*h[i][n]) / x
+						h[i+1][n] = (-sa - w*h[i][n] - q*h[i][n-1]) / x
+					} else {
+						h[i+1][n-1], h[i+1][n] = cdiv(-r-y*h[i][n-1], -s-y*h[i][n], z, q)
+					}
+				}
+				// Overflow control.
+				t = math.Max(math.Abs(h[i][n-1]), math.Abs(h[i][n]))
+				if (eps*t)*t > 1 {
+					for j := i; j <= n; j++ {
+						h[j][n-1] /= t
+						h[j][n] /= t
+					}
+				}
+			}
+		}
+	}
+	// Back transformation to get the eigenvectors of the original matrix.
+	for j := nn - 1; j >= 0; j-- {
+		for i := 0; i < nn; i++ {
+			z = 0.0
+			for k := 0; k <= j; k++ {
+				z += v[i][k] * h[k][j]
+			}
+			v[i][j] = z
+		}
+	}
+}
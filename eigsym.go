@@ -0,0 +1,92 @@
+package matf64
+
+import (
+	"math"
+	"sort"
+)
+
+/*
+EigSym computes the eigenvalues and eigenvectors of a symmetric [][]float64
+using the cyclic Jacobi method. The eigenvalues are returned in ascending
+order, and the i-th column of vecs is the unit eigenvector belonging to the
+i-th eigenvalue, so that
+
+	Dot(m, vecs) = Dot(vecs, D)
+
+where D is the [][]float64 with vals along its diagonal. The columns of vecs are
+orthonormal. Only the lower triangle of m, including the diagonal, is read, and
+the upper triangle is assumed to mirror it. ErrNoConvergence is returned if the
+Jacobi sweeps fail to converge. The original [][]float64 is not mutated in this
+function.
+*/
+func EigSym(m [][]float64) (vals []float64, vecs [][]float64, err error) {
+	checkSquare("EigSym()", m)
+	n := len(m)
+	a := New(n)
+	norm := 0.0
+	for i := range m {
+		for j := 0; j <= i; j++ {
+			a[i][j] = m[i][j]
+			a[j][i] = m[i][j]
+			norm += m[i][j] * m[i][j]
+		}
+	}
+	v := I(n)
+	tiny := norm * eps * eps
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps && !converged; sweep++ {
+		off := 0.0
+		for p := 0; p < n; p++ {
+			for q := p + 1; q < n; q++ {
+				off += a[p][q] * a[p][q]
+			}
+		}
+		if off <= tiny {
+			converged = true
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				if a[p][q] == 0.0 {
+					continue
+				}
+				theta := (a[q][q] - a[p][p]) / (2.0 * a[p][q])
+				t := math.Copysign(1.0, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1.0))
+				c := 1.0 / math.Sqrt(t*t+1.0)
+				s := c * t
+				for k := 0; k < n; k++ {
+					akp, akq := a[k][p], a[k][q]
+					a[k][p] = c*akp - s*akq
+					a[k][q] = s*akp + c*akq
+				}
+				for k := 0; k < n; k++ {
+					apk, aqk := a[p][k], a[q][k]
+					a[p][k] = c*apk - s*aqk
+					a[q][k] = s*apk + c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k][p], v[k][q]
+					v[k][p] = c*vkp - s*vkq
+					v[k][q] = s*vkp + c*vkq
+				}
+			}
+		}
+	}
+	if !converged {
+		return nil, nil, ErrNoConvergence
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return a[idx[i]][idx[i]] < a[idx[j]][idx[j]] })
+	vals = make([]float64, n)
+	vecs = New(n)
+	for j, k := range idx {
+		vals[j] = a[k][k]
+		for i := range vecs {
+			vecs[i][j] = v[i][k]
+		}
+	}
+	return vals, vecs, nil
+}
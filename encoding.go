@@ -0,0 +1,137 @@
+package matf64
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+/*
+The binary encoding written by Encode starts with a 24 byte header, holding
+the magic bytes "MF64", the version as a uint16, two reserved zero bytes, and
+the number of rows and columns as uint64s. The entries follow in row major
+order as float64s, and a CRC-32 (IEEE) checksum of everything before it closes
+the encoding. All numbers are little endian.
+*/
+const (
+	encodingMagic   = "MF64"
+	encodingVersion = 1
+	encodingHeader  = 24
+	encodingChunk   = 1 << 12
+	// encodingMaxEmpty bounds the number of rows without columns, which are
+	// not backed by any data in the encoding.
+	encodingMaxEmpty = 1 << 16
+)
+
+/*
+Encode writes a [][]float64 to w in a compact binary encoding, which can be
+read back with Decode. For example:
+
+	err := matf64.Encode(f, m)
+
+The entries are written one row at a time as they are encoded, so the whole
+encoding is never held in memory. A JaggedError is returned if m is jagged, and
+an ArgError if it has more than 65536 rows without any columns.
+*/
+func Encode(w io.Writer, m [][]float64) error {
+	rows, cols, err := shape("Encode()", m)
+	if err != nil {
+		return err
+	}
+	if cols == 0 && rows > encodingMaxEmpty {
+		s := "at most %d empty rows can be encoded, but received %d"
+		return &ArgError{Op: "Encode()", Msg: fmt.Sprintf(s, encodingMaxEmpty, rows)}
+	}
+	crc := crc32.NewIEEE()
+	mw := io.MultiWriter(w, crc)
+	header := make([]byte, encodingHeader)
+	copy(header, encodingMagic)
+	binary.LittleEndian.PutUint16(header[4:], encodingVersion)
+	binary.LittleEndian.PutUint64(header[8:], uint64(rows))
+	binary.LittleEndian.PutUint64(header[16:], uint64(cols))
+	if _, err := mw.Write(header); err != nil {
+		return err
+	}
+	b := make([]byte, 8*cols)
+	for i := range m {
+		for j, x := range m[i] {
+			binary.LittleEndian.PutUint64(b[8*j:], math.Float64bits(x))
+		}
+		if _, err := mw.Write(b); err != nil {
+			return err
+		}
+	}
+	var sum [4]byte
+	binary.LittleEndian.PutUint32(sum[:], crc.Sum32())
+	_, err = w.Write(sum[:])
+	return err
+}
+
+/*
+Decode reads a [][]float64 written by Encode from r. For example:
+
+	m, err := matf64.Decode(f)
+
+The encoding is read one row at a time, so memory is only allocated for the
+data which is actually present. io.ErrUnexpectedEOF is returned if the input
+is truncated, and a FormatError if it is not an encoding of a [][]float64, was
+written by a newer version of this library, or does not match its checksum.
+*/
+func Decode(r io.Reader) ([][]float64, error) {
+	const op = "Decode()"
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+	header := make([]byte, encodingHeader)
+	if _, err := io.ReadFull(tr, header); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if string(header[:4]) != encodingMagic {
+		return nil, &FormatError{Op: op, Msg: "not an encoded [][]float64"}
+	}
+	if v := binary.LittleEndian.Uint16(header[4:]); v != encodingVersion {
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf("unsupported version %d", v)}
+	}
+	rows := binary.LittleEndian.Uint64(header[8:])
+	cols := binary.LittleEndian.Uint64(header[16:])
+	if rows > math.MaxInt32 || cols > math.MaxInt32 || (rows == 0 && cols != 0) || (cols == 0 && rows > encodingMaxEmpty) {
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf("invalid shape %dx%d", rows, cols)}
+	}
+	var m [][]float64
+	if rows == 0 {
+		m = [][]float64{}
+	}
+	// The rows are read in chunks of at most encodingChunk entries, so that a
+	// corrupted shape can not cause a huge allocation up front. Rows without
+	// columns read no data, which is why their number is bounded above.
+	chunk := cols
+	if chunk > encodingChunk {
+		chunk = encodingChunk
+	}
+	b := make([]byte, 8*chunk)
+	for i := uint64(0); i < rows; i++ {
+		row := make([]float64, 0, chunk)
+		for j := uint64(0); j < cols; j += chunk {
+			n := cols - j
+			if n > chunk {
+				n = chunk
+			}
+			if _, err := io.ReadFull(tr, b[:8*n]); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			for k := uint64(0); k < n; k++ {
+				row = append(row, math.Float64frombits(binary.LittleEndian.Uint64(b[8*k:])))
+			}
+		}
+		m = append(m, row)
+	}
+	var sum [4]byte
+	if _, err := io.ReadFull(r, sum[:]); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if binary.LittleEndian.Uint32(sum[:]) != crc.Sum32() {
+		return nil, &FormatError{Op: op, Msg: "checksum mismatch, the data is corrupted"}
+	}
+	return m, nil
+}
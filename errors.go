@@ -0,0 +1,234 @@
+package matf64
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+The following errors are reported by the Try variants of the functions in this
+library, such as TryDot and TrySum. These validate their arguments and return
+an error where the plain functions would panic or index out of range, so that
+bad input, such as a malformed request to a server, can not crash a program.
+The errors are wrapped by the more detailed error types below, so they are
+best checked with errors.Is, for example:
+
+	if _, err := matf64.TryDot(m, n); errors.Is(err, matf64.ErrShapeMismatch) {
+		...
+	}
+*/
+var (
+	ErrShapeMismatch   = errors.New("matf64: shape mismatch")
+	ErrJagged          = errors.New("matf64: jagged [][]float64")
+	ErrIndexOutOfRange = errors.New("matf64: index out of range")
+	ErrInvalidArgument = errors.New("matf64: invalid argument")
+)
+
+/*
+ShapeError describes a [][]float64 or []float64 whose dimensions do not fit the
+operation it was passed to. Rows and Cols are the dimensions which were
+received, while WantRows and WantCols are the dimensions which were expected,
+with -1 standing for any size. A []float64 is described as a single row.
+ShapeError wraps ErrShapeMismatch.
+*/
+type ShapeError struct {
+	Op                 string
+	Rows, Cols         int
+	WantRows, WantCols int
+}
+
+func (e *ShapeError) Error() string {
+	dim := func(d int) string {
+		if d < 0 {
+			return "*"
+		}
+		return fmt.Sprint(d)
+	}
+	s := "matf64.%s: shape mismatch, expected %sx%s but received %dx%d"
+	return fmt.Sprintf(s, e.Op, dim(e.WantRows), dim(e.WantCols), e.Rows, e.Cols)
+}
+
+// Unwrap returns ErrShapeMismatch.
+func (e *ShapeError) Unwrap() error { return ErrShapeMismatch }
+
+/*
+JaggedError describes a [][]float64 whose rows do not all have the same
+length. Row is the index of the first offending row, which has Len entries
+while the rows before it have Want entries. JaggedError wraps ErrJagged.
+*/
+type JaggedError struct {
+	Op        string
+	Row       int
+	Len, Want int
+}
+
+func (e *JaggedError) Error() string {
+	s := "matf64.%s: jagged [][]float64, row %d has %d entries but expected %d"
+	return fmt.Sprintf(s, e.Op, e.Row, e.Len, e.Want)
+}
+
+// Unwrap returns ErrJagged.
+func (e *JaggedError) Unwrap() error { return ErrJagged }
+
+/*
+IndexError describes a row or column index, possibly negative, which is out
+of range for a dimension of length Len. IndexError wraps ErrIndexOutOfRange.
+*/
+type IndexError struct {
+	Op    string
+	Index int
+	Len   int
+}
+
+func (e *IndexError) Error() string {
+	s := "matf64.%s: index %d out of range for length %d"
+	return fmt.Sprintf(s, e.Op, e.Index, e.Len)
+}
+
+// Unwrap returns ErrIndexOutOfRange.
+func (e *IndexError) Unwrap() error { return ErrIndexOutOfRange }
+
+/*
+ArgError describes any other invalid argument, such as the wrong number of
+optional arguments or an argument of an unsupported type. ArgError wraps
+ErrInvalidArgument.
+*/
+type ArgError struct {
+	Op  string
+	Msg string
+}
+
+func (e *ArgError) Error() string {
+	return fmt.Sprintf("matf64.%s: %s", e.Op, e.Msg)
+}
+
+// Unwrap returns ErrInvalidArgument.
+func (e *ArgError) Unwrap() error { return ErrInvalidArgument }
+
+/*
+shape returns the number of rows and columns of m, or a JaggedError naming op
+if the rows of m do not all have the same length.
+*/
+func shape(op string, m [][]float64) (rows, cols int, err error) {
+	if len(m) == 0 {
+		return 0, 0, nil
+	}
+	cols = len(m[0])
+	for i := range m {
+		if len(m[i]) != cols {
+			return 0, 0, &JaggedError{Op: op, Row: i, Len: len(m[i]), Want: cols}
+		}
+	}
+	return len(m), cols, nil
+}
+
+/*
+index resolves a possibly negative index into a dimension of length n, in the
+same way as Row and Col, returning an IndexError naming op if it is out of range.
+*/
+func index(op string, x, n int) (int, error) {
+	i := x
+	if i < 0 {
+		i += n
+	}
+	if i < 0 || i >= n {
+		return 0, &IndexError{Op: op, Index: x, Len: n}
+	}
+	return i, nil
+}
+
+/*
+checkAxisArgs validates the optional (axis, index) arguments accepted by Sum,
+Prod and Avg against the shape of m.
+*/
+func checkAxisArgs(op string, m [][]float64, args []int) error {
+	rows, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	switch len(args) {
+	case 0:
+		return nil
+	case 2:
+		switch args[0] {
+		case 0:
+			_, err = index(op, args[1], rows)
+		case 1:
+			_, err = index(op, args[1], cols)
+		default:
+			s := "the axis must be 0 for row, or 1 for column, but %d was passed"
+			err = &ArgError{Op: op, Msg: fmt.Sprintf(s, args[0])}
+		}
+		return err
+	default:
+		s := "expected 0 or 2 arguments after the [][]float64, but received %d"
+		return &ArgError{Op: op, Msg: fmt.Sprintf(s, len(args))}
+	}
+}
+
+/*
+checkOperand validates the second argument of Mult, Add, Sub and Div against
+the shape of m.
+*/
+func checkOperand(op string, m [][]float64, val interface{}) error {
+	rows, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	switch v := val.(type) {
+	case float64:
+		return nil
+	case []float64:
+		if rows > 0 && len(v) != cols {
+			return &ShapeError{Op: op, Rows: 1, Cols: len(v), WantRows: 1, WantCols: cols}
+		}
+		return nil
+	case [][]float64:
+		r, c, err := shape(op, v)
+		if err != nil {
+			return err
+		}
+		if r != rows || c != cols {
+			return &ShapeError{Op: op, Rows: r, Cols: c, WantRows: rows, WantCols: cols}
+		}
+		return nil
+	default:
+		s := "expected float64, []float64, or [][]float64 for the second argument, but received %T"
+		return &ArgError{Op: op, Msg: fmt.Sprintf(s, v)}
+	}
+}
+
+/*
+checkRange validates the optional range arguments accepted by RandMat and
+RandVec.
+*/
+func checkRange(op string, args []float64) error {
+	if len(args) > 2 {
+		s := "expected 0-2 float64s for the range, but received %d"
+		return &ArgError{Op: op, Msg: fmt.Sprintf(s, len(args))}
+	}
+	return nil
+}
+
+/*
+checkTol validates the optional tolerance accepted by PInv, Rank and NullSpace.
+*/
+func checkTol(op string, tol []float64) error {
+	if len(tol) > 1 {
+		s := "expected 0 or 1 float64s for the tolerance, but received %d"
+		return &ArgError{Op: op, Msg: fmt.Sprintf(s, len(tol))}
+	}
+	return nil
+}
+
+/*
+checkDims validates that all the passed dimensions are non-negative.
+*/
+func checkDims(op string, dims ...int) error {
+	for _, d := range dims {
+		if d < 0 {
+			return &ArgError{Op: op, Msg: fmt.Sprintf("negative dimension %d", d)}
+		}
+	}
+	return nil
+}
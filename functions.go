@@ -44,3 +44,35 @@ func NewReducer(initialValue float64, f BinaryFn) ReducerFn {
 		return initialValue
 	}
 }
+
+/*
+AxisReducerFn are functions which aggregate each row or each column of a
+[][]float64, such as finding the sum of every column.
+*/
+type AxisReducerFn func([][]float64, int) []float64
+
+/*
+NewAxisReducer is the counterpart of NewReducer, generating a function which
+reduces each row or each column of a [][]float64 into a []float64, with the
+axis chosen as in SumAxis. Each row or column starts from initialValue. For
+example:
+
+	sum := matf64.NewAxisReducer(0, func(i *float64, j *float64) {
+		*i += *j
+	})
+
+	m := matf64.New(4, 3)
+	matf64.Set(m, 2.0)
+	s := sum(m, 1) // s is [8.0, 8.0, 8.0]
+*/
+func NewAxisReducer(initialValue float64, f BinaryFn) AxisReducerFn {
+	return func(m [][]float64, axis int) []float64 {
+		if debug {
+			check(checkRect("NewAxisReducer()", m))
+		}
+		return reduceAxis("NewAxisReducer()", m, axis, initialValue, func(acc, x float64) float64 {
+			f(&acc, &x)
+			return acc
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+LogProd returns the natural logarithm of the magnitude of the product of all
+elements in a [][]float64, along with the sign of the product, which is -1.0,
+0.0 or 1.0. The product itself is
+
+	sign * math.Exp(logAbs)
+
+Unlike Prod, LogProd does not overflow to Inf or underflow to 0 when
+multiplying thousands of very large or very small values, such as the
+probabilities in a likelihood. A product with a zero element has a logAbs of
+-Inf and a sign of 0.0, and a NaN element makes logAbs NaN. As with Prod, the
+product of a specific row or column can be found by passing two additional
+integers:
+
+	logAbs, sign := matf64.LogProd(m, 1, 0)
+
+The original [][]float64 is not mutated in this function.
+*/
+func LogProd(m [][]float64, args ...int) (logAbs, sign float64) {
+	v, _ := selectValues("LogProd()", m, args)
+	return logProd(v)
+}
+
+/*
+LogSumExp returns the natural logarithm of the sum of the exponentials of all
+elements in a [][]float64, which is the sum of values stored as logarithms.
+The largest element is factored out before exponentiating, so that the result
+neither overflows nor underflows. As with Sum, the row or column to reduce can
+be selected by passing two additional integers:
+
+	matf64.LogSumExp(m, 0, -1)
+
+The original [][]float64 is not mutated in this function.
+*/
+func LogSumExp(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("LogSumExp()", m, args)
+	return logSumExp(v)
+}
+
+/*
+LogProdAxis returns the result of LogProd for every row or every column of a
+[][]float64, with the axis chosen as in SumAxis.
+*/
+func LogProdAxis(m [][]float64, axis int) (logAbs, sign []float64) {
+	lines := axisLines("LogProdAxis()", m, axis)
+	logAbs = make([]float64, len(lines))
+	sign = make([]float64, len(lines))
+	for i := range lines {
+		logAbs[i], sign[i] = logProd(lines[i])
+	}
+	return logAbs, sign
+}
+
+/*
+LogSumExpAxis returns the result of LogSumExp for every row or every column of
+a [][]float64, with the axis chosen as in SumAxis.
+*/
+func LogSumExpAxis(m [][]float64, axis int) []float64 {
+	lines := axisLines("LogSumExpAxis()", m, axis)
+	v := make([]float64, len(lines))
+	for i := range lines {
+		v[i] = logSumExp(lines[i])
+	}
+	return v
+}
+
+/*
+axisLines returns the rows of m for axis 0, or a copy of its columns for axis
+1. The name of the calling function is used in the panic message for an
+invalid axis.
+*/
+func axisLines(caller string, m [][]float64, axis int) [][]float64 {
+	if debug {
+		check(checkRect(caller, m))
+	}
+	switch axis {
+	case 0:
+		return m
+	case 1:
+		if len(m) == 0 {
+			return nil
+		}
+		return T(m)
+	default:
+		s := "In matf64.%s the axis must be 0 for rows, or 1 for columns, but %d was passed."
+		s = fmt.Sprintf(s, caller, axis)
+		panic(s)
+	}
+}
+
+/*
+logProd returns the logarithm of the magnitude and the sign of the product of
+the elements of v.
+*/
+func logProd(v []float64) (logAbs, sign float64) {
+	logs := make([]float64, len(v))
+	sign = 1.0
+	for i := range v {
+		switch {
+		case v[i] == 0.0:
+			sign = 0.0
+		case v[i] < 0.0:
+			sign = -sign
+		}
+		logs[i] = math.Log(math.Abs(v[i]))
+	}
+	return KahanSum.SumVec(logs), sign
+}
+
+/*
+logSumExp returns the logarithm of the sum of the exponentials of v.
+*/
+func logSumExp(v []float64) float64 {
+	max := math.Inf(-1)
+	for i := range v {
+		if math.IsNaN(v[i]) {
+			return math.NaN()
+		}
+		max = math.Max(max, v[i])
+	}
+	if math.IsInf(max, 0) {
+		return max
+	}
+	sum := 0.0
+	for i := range v {
+		sum += math.Exp(v[i] - max)
+	}
+	return max + math.Log(sum)
+}
@@ -0,0 +1,213 @@
+package matf64
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+/*
+ErrSingular is returned by the functions which solve linear systems or invert
+[][]float64s when the passed [][]float64 is singular, or so close to singular
+that the result would be dominated by rounding errors.
+*/
+var ErrSingular = errors.New("matf64: matrix is singular or nearly singular")
+
+// eps is the machine epsilon of float64, used to scale singularity tolerances.
+const eps = 2.220446049250313e-16
+
+/*
+LU computes the LU decomposition of a square [][]float64 using Gaussian
+elimination with partial pivoting. The decomposition satisfies
+
+	P * m = l * u
+
+where l is unit lower triangular, u is upper triangular, and P is the
+permutation matrix described by p: row i of P * m is row p[i] of m. For example:
+
+	l, u, p, err := matf64.LU(m)
+
+ErrSingular is returned if m is singular or nearly singular, in which case the
+returned factors are still populated, but can not be used to solve systems
+reliably. The original [][]float64 is not mutated in this function.
+*/
+func LU(m [][]float64) (l, u [][]float64, p []int, err error) {
+	lu, p, _, singular := luDecomp("LU()", m)
+	n := len(lu)
+	l = New(n)
+	u = New(n)
+	for i := range lu {
+		for j := range lu[i] {
+			switch {
+			case i > j:
+				l[i][j] = lu[i][j]
+			case i == j:
+				l[i][j] = 1.0
+				u[i][j] = lu[i][j]
+			default:
+				u[i][j] = lu[i][j]
+			}
+		}
+	}
+	if singular {
+		err = ErrSingular
+	}
+	return l, u, p, err
+}
+
+/*
+Solve finds x in the linear system a * x = b, where a is a square [][]float64
+and b is a []float64 with as many entries as a has rows. For example:
+
+	x, err := matf64.Solve(a, b)
+
+ErrSingular is returned if a is singular or nearly singular. The passed
+arguments are not mutated by this function.
+*/
+func Solve(a [][]float64, b []float64) ([]float64, error) {
+	if len(b) != len(a) {
+		s := "In matf64.%s the []float64 has %d entries, but the [][]float64 has %d rows."
+		s = fmt.Sprintf(s, "Solve()", len(b), len(a))
+		panic(s)
+	}
+	lu, p, _, singular := luDecomp("Solve()", a)
+	if singular {
+		return nil, ErrSingular
+	}
+	return luSolve(lu, p, b), nil
+}
+
+/*
+Inverse returns the inverse of a square [][]float64, computed from its LU
+decomposition. ErrSingular is returned if the passed [][]float64 is singular or
+nearly singular. The original [][]float64 is not mutated in this function.
+*/
+func Inverse(m [][]float64) ([][]float64, error) {
+	lu, p, _, singular := luDecomp("Inverse()", m)
+	if singular {
+		return nil, ErrSingular
+	}
+	n := len(lu)
+	inv := New(n)
+	e := make([]float64, n)
+	for j := 0; j < n; j++ {
+		for i := range e {
+			e[i] = 0.0
+		}
+		e[j] = 1.0
+		x := luSolve(lu, p, e)
+		for i := range x {
+			inv[i][j] = x[i]
+		}
+	}
+	return inv, nil
+}
+
+/*
+Det returns the determinant of a square [][]float64, computed as the product
+of the diagonal of its LU decomposition. Unlike Solve and Inverse, Det does not
+treat nearly singular input as an error: the (possibly tiny) determinant is
+returned as is. The original [][]float64 is not mutated in this function.
+*/
+func Det(m [][]float64) float64 {
+	lu, _, sign, _ := luDecomp("Det()", m)
+	det := sign
+	for i := range lu {
+		det *= lu[i][i]
+	}
+	return det
+}
+
+/*
+luDecomp computes the packed LU decomposition of m, with the strictly lower
+part holding l (without its unit diagonal) and the upper part holding u. It
+also returns the pivot permutation, the sign of that permutation, and whether
+a pivot fell below the singularity tolerance. The name of the calling function
+is used in the panic message for non-square input.
+*/
+func luDecomp(caller string, m [][]float64) (lu [][]float64, p []int, sign float64, singular bool) {
+	n := len(m)
+	checkSquare(caller, m)
+	lu = Clone(m)
+	p = make([]int, n)
+	for i := range p {
+		p[i] = i
+	}
+	sign = 1.0
+	maxAbs := 0.0
+	for i := range lu {
+		for j := range lu[i] {
+			maxAbs = math.Max(maxAbs, math.Abs(lu[i][j]))
+		}
+	}
+	tol := float64(n) * maxAbs * eps
+	for k := 0; k < n; k++ {
+		piv := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[i][k]) > math.Abs(lu[piv][k]) {
+				piv = i
+			}
+		}
+		if piv != k {
+			lu[piv], lu[k] = lu[k], lu[piv]
+			p[piv], p[k] = p[k], p[piv]
+			sign = -sign
+		}
+		if math.Abs(lu[k][k]) <= tol {
+			singular = true
+			if lu[k][k] == 0.0 {
+				continue
+			}
+		}
+		for i := k + 1; i < n; i++ {
+			lu[i][k] /= lu[k][k]
+			f := lu[i][k]
+			if f == 0.0 {
+				continue
+			}
+			for j := k + 1; j < n; j++ {
+				lu[i][j] -= f * lu[k][j]
+			}
+		}
+	}
+	return lu, p, sign, singular
+}
+
+/*
+luSolve solves the system described by a packed LU decomposition and its pivot
+permutation for the right hand side b, which is not mutated.
+*/
+func luSolve(lu [][]float64, p []int, b []float64) []float64 {
+	n := len(lu)
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = b[p[i]]
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < i; j++ {
+			x[i] -= lu[i][j] * x[j]
+		}
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := i + 1; j < n; j++ {
+			x[i] -= lu[i][j] * x[j]
+		}
+		x[i] /= lu[i][i]
+	}
+	return x
+}
+
+/*
+checkSquare panics if m is not a square [][]float64, naming the calling
+function in the panic message.
+*/
+func checkSquare(caller string, m [][]float64) {
+	for i := range m {
+		if len(m[i]) != len(m) {
+			s := "In matf64.%s expected a square [][]float64, but row %d has %d entries\n"
+			s += "while there are %d rows."
+			s = fmt.Sprintf(s, caller, i, len(m[i]), len(m))
+			panic(s)
+		}
+	}
+}
@@ -16,12 +16,21 @@ all the functions in this library act on Go primitive types, which allows the co
 to be easily modified to serve in different situations, and to easily integrate with
 existing code bases
 
+For the sake of speed, most functions assume that the passed [][]float64s are
+non-jagged and have compatible dimensions, without checking. Building with the
+matf64debug tag enables a checked mode, in which the functions verify their
+arguments before operating, and panic with a JaggedError, ShapeError or
+IndexError describing the problem, such as the exact row which is ragged:
+
+	go test -tags matf64debug ./...
+
 */
 package matf64
 
 import (
 	"fmt"
-	"math/rand"
+	"runtime"
+	"sync"
 )
 
 /*
@@ -38,6 +47,9 @@ is a [][]float64 with x rows and y columns.
 
 */
 func New(dims ...int) [][]float64 {
+	if debug {
+		check(checkDims("New()", dims...))
+	}
 	var m [][]float64
 	switch len(dims) {
 	case 1:
@@ -66,6 +78,9 @@ I returns a square [][]float64 with all elements along the diagonal equal to
 1.0, and 0.0 elsewhere. This is the identity matrix.
 */
 func I(x int) [][]float64 {
+	if debug {
+		check(checkDims("I()", x))
+	}
 	m := New(x)
 	for i := range m {
 		m[i][i] = 1.0
@@ -75,63 +90,28 @@ func I(x int) [][]float64 {
 
 /*
 RandMat creates a x by y [][]float64 with the entries set to random numbers in the
-range [0, 1) (including 0, but excluding 1).
+range [0, 1) (including 0, but excluding 1), using the global source of the
+math/rand package.
 
-A n by n [][]float64 can be created if one int is passed to this constructor, where
-a n by m matrix is created when two ints are passed.
+The range can be changed by passing the upper bound, or both the lower and
+upper bounds:
+
+	m := matf64.RandMat(3, 4, -1.0, 1.0)
+
+creates a 3 by 4 [][]float64 with entries in [-1, 1). For reproducible values,
+use the RandMat method of a Rand created with NewRand.
 */
 func RandMat(x, y int, args ...float64) [][]float64 {
-	m := New(y, y)
-	var from float64
-	var to float64
-
-	switch len(args) {
-	case 0:
-		to = 1
-	case 1:
-		to = args[0]
-	case 2:
-		from = args[0]
-		to = args[1]
-	default:
-		s := "In matf64.%s expected 0-2 float64s for the range, but recieved %d"
-		s = fmt.Sprintf(s, "RandMat()", len(args))
-		panic(s)
-	}
-	for i := range m {
-		for j := range m[i] {
-			m[i][j] = rand.Float64()*(to-from) + from
-		}
-	}
-	return m
+	return global.RandMat(x, y, args...)
 }
 
 /*
 RandVec returns a []float64 with the entries set to random number in the
-range [0, 1)
+range [0, 1), using the global source of the math/rand package. The range can
+be changed as for RandMat.
 */
 func RandVec(size int, args ...float64) []float64 {
-	v := make([]float64, size)
-	var from float64
-	var to float64
-
-	switch len(args) {
-	case 0:
-		to = 1
-	case 1:
-		to = args[0]
-	case 2:
-		from = args[0]
-		to = args[1]
-	default:
-		s := "In matf64.%s expected 0-2 float64s for the range, but recieved %d"
-		s = fmt.Sprintf(s, "RandVec()", len(args))
-		panic(s)
-	}
-	for i := range v {
-		v[i] = rand.Float64()*(to-from) + from
-	}
-	return v
+	return global.RandVec(size, args...)
 }
 
 /*
@@ -168,6 +148,9 @@ Col also accepts negative indices. For example:
 The original [][]float64 is not mutated in this function.
 */
 func Col(m [][]float64, x int) []float64 {
+	if debug {
+		check(checkIndex("Col()", m, 1, x))
+	}
 	v := make([]float64, len(m))
 	if x >= 0 {
 		for i := range m {
@@ -194,6 +177,9 @@ Row also accepts negative indices. For example:
 The original [][]float64 is not mutated in this function.
 */
 func Row(m [][]float64, x int) []float64 {
+	if debug {
+		check(checkIndex("Row()", m, 0, x))
+	}
 	v := make([]float64, len(m[0]))
 	if x >= 0 {
 		copy(v, m[x])
@@ -251,6 +237,9 @@ respectively. This method creates a new [][]float64, and the original is
 left intact. The passed [][]float64 is assumed to be non-jagged.
 */
 func T(m [][]float64) [][]float64 {
+	if debug {
+		check(checkRect("T()", m))
+	}
 	n := New(len(m[0]), len(m))
 	for i := range m {
 		for j := range m[i] {
@@ -335,6 +324,9 @@ where as the sum of the first column is given by:
 The original [][]float64 is not mutated in this function.
 */
 func Sum(m [][]float64, args ...int) float64 {
+	if debug {
+		check(checkAxisArgs("Sum()", m, args))
+	}
 	sum := 0.0
 	switch len(args) {
 	case 0:
@@ -405,6 +397,9 @@ where as the product of the first column is given by:
 The original [][]float64 is not mutated in this function.
 */
 func Prod(m [][]float64, args ...int) float64 {
+	if debug {
+		check(checkAxisArgs("Prod()", m, args))
+	}
 	prod := 1.0
 	switch len(args) {
 	case 0:
@@ -478,6 +473,9 @@ where as the sum of the first column is given by:
 The original [][]float64 is not mutated in this function.
 */
 func Avg(m [][]float64, args ...int) float64 {
+	if debug {
+		check(checkAxisArgs("Avg()", m, args))
+	}
 	avg := 0.0
 	sum := 0.0
 	numItems := 0
@@ -546,19 +544,88 @@ The regular rules of a dot product hold: for any two [][]float64s passed to
 this function, the number of columns of the first must be equal to the number
 of rows of the second. The resulting [][]float64 has the same number of rows
 as the first [][]float64 and the same number of columns as the second.
+
+The product is computed in cache sized blocks, walking along the rows of both
+[][]float64s, and large products are split by rows across goroutines. Each
+entry of the result is still accumulated in the same order as the textbook
+triple loop, so the result does not depend on the blocking or on the number
+of goroutines used.
 */
 func Dot(m, n [][]float64) [][]float64 {
+	if debug {
+		check(checkDot("Dot()", m, n))
+	}
 	res := New(len(m), len(n[0]))
-	for i := range m {
-		for j := range n[0] {
-			for k := range m[i] {
-				res[i][j] += m[i][k] * n[k][j]
-			}
+	if len(m) == 0 {
+		return res
+	}
+	inner := len(m[0])
+	workers := runtime.GOMAXPROCS(0)
+	if workers > len(m) {
+		workers = len(m)
+	}
+	if workers < 2 || len(m)*inner*len(n[0]) < dotParallelWork {
+		dotRows(res, m, n, 0, len(m))
+		return res
+	}
+	var wg sync.WaitGroup
+	chunk := (len(m) + workers - 1) / workers
+	for lo := 0; lo < len(m); lo += chunk {
+		hi := lo + chunk
+		if hi > len(m) {
+			hi = len(m)
 		}
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			dotRows(res, m, n, lo, hi)
+		}(lo, hi)
 	}
+	wg.Wait()
 	return res
 }
 
+const (
+	// dotBlock is the edge length of the blocks which Dot works on at a time.
+	dotBlock = 128
+	// dotParallelWork is the number of multiply-adds above which Dot uses
+	// multiple goroutines.
+	dotParallelWork = 1 << 20
+)
+
+/*
+dotRows accumulates rows lo through hi-1 of the matrix product of m and n into
+res. The inner dimension and the columns of n are walked in blocks, so that the
+rows of n being used stay in cache, while every entry of res still sums its
+terms in order of increasing inner index.
+*/
+func dotRows(res, m, n [][]float64, lo, hi int) {
+	inner := len(m[0])
+	cols := len(n[0])
+	for kk := 0; kk < inner; kk += dotBlock {
+		kEnd := kk + dotBlock
+		if kEnd > inner {
+			kEnd = inner
+		}
+		for jj := 0; jj < cols; jj += dotBlock {
+			jEnd := jj + dotBlock
+			if jEnd > cols {
+				jEnd = cols
+			}
+			for i := lo; i < hi; i++ {
+				ri := res[i][jj:jEnd]
+				for k := kk; k < kEnd; k++ {
+					a := m[i][k]
+					nk := n[k][jj:jEnd]
+					for j := range ri {
+						ri[j] += a * nk[j]
+					}
+				}
+			}
+		}
+	}
+}
+
 /*
 AppendCol returns a copy of a passed [][]float64, with the second argument, a
 []float64, appended to its right side. For example, consider:
@@ -570,6 +637,10 @@ AppendCol returns a copy of a passed [][]float64, with the second argument, a
 The passed arguments are not mutated by this function.
 */
 func AppendCol(m [][]float64, v []float64) {
+	if debug {
+		check(checkRect("AppendCol()", m))
+		check(checkLen("AppendCol()", v, len(m)))
+	}
 	for i := range v {
 		m[i] = append(m[i], v[i])
 	}
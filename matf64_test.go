@@ -1,6 +1,17 @@
 package matf64
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -351,6 +362,9 @@ func TestRandMat(t *testing.T) {
 	row := 31
 	col := 42
 	m := RandMat(row, col)
+	if len(m) != row || len(m[0]) != col {
+		t.Errorf("expected a %dx%d [][]float64, got %dx%d", row, col, len(m), len(m[0]))
+	}
 	for i := range m {
 		for j := range m[i] {
 			if m[i][j] < 0.0 || m[i][j] >= 1.0 {
@@ -686,20 +700,52 @@ func TestDot(t *testing.T) {
 	}
 }
 
-func BenchmarkDot(b *testing.B) {
-	m := New(1000)
-	n := New(1000)
-	for i := range m {
-		for j := range m[i] {
-			m[i][j] = float64(i*10 + j)
+func TestDotMatchesNaive(t *testing.T) {
+	t.Helper()
+	for _, dims := range [][3]int{{1, 1, 1}, {3, 7, 5}, {17, 130, 9}, {150, 140, 260}} {
+		m := New(dims[0], dims[1])
+		n := New(dims[1], dims[2])
+		for i := range m {
+			for j := range m[i] {
+				m[i][j] = math.Sin(float64(i*dims[1]+j)) * 1e3
+			}
+		}
+		for i := range n {
+			for j := range n[i] {
+				n[i][j] = math.Cos(float64(i*dims[2] + j))
+			}
+		}
+		want := New(dims[0], dims[2])
+		for i := range m {
+			for j := range n[0] {
+				for k := range m[i] {
+					want[i][j] += m[i][k] * n[k][j]
+				}
+			}
+		}
+		if got := Dot(m, n); !Equal(got, want) {
+			t.Errorf("Dot of %dx%d and %dx%d differs from the naive product", dims[0], dims[1], dims[1], dims[2])
 		}
 	}
-	for i := range n {
-		n[i][i] = 1.0
-	}
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		_ = Dot(m, n)
+}
+
+func BenchmarkDot(b *testing.B) {
+	for _, size := range []int{10, 100, 500, 1000} {
+		m := New(size)
+		n := New(size)
+		for i := range m {
+			for j := range m[i] {
+				m[i][j] = float64(i*10 + j)
+			}
+		}
+		for i := range n {
+			n[i][i] = 1.0
+		}
+		b.Run(fmt.Sprintf("%dx%d", size, size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = Dot(m, n)
+			}
+		})
 	}
 }
 
@@ -714,3 +760,1760 @@ func TestAppendCol(t *testing.T) {
 		}
 	}
 }
+
+func TestLU(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{2.0, 1.0, 1.0},
+		{4.0, -6.0, 0.0},
+		{-2.0, 7.0, 2.0},
+	}
+	l, u, p, err := LU(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	pm := New(len(m))
+	for i := range p {
+		copy(pm[i], m[p[i]])
+	}
+	lu := Dot(l, u)
+	for i := range lu {
+		for j := range lu[i] {
+			if math.Abs(lu[i][j]-pm[i][j]) > 1e-12 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, pm[i][j], lu[i][j])
+			}
+		}
+	}
+	for i := range l {
+		if l[i][i] != 1.0 {
+			t.Errorf("expected unit diagonal in l, got %f", l[i][i])
+		}
+		for j := i + 1; j < len(l); j++ {
+			if l[i][j] != 0.0 || u[j][i] != 0.0 {
+				t.Errorf("factors are not triangular at (%d, %d)", i, j)
+			}
+		}
+	}
+	_, _, _, err = LU([][]float64{{1.0, 2.0}, {2.0, 4.0}})
+	if err != ErrSingular {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+}
+
+func TestSolve(t *testing.T) {
+	t.Helper()
+	a := [][]float64{
+		{3.0, 2.0, -1.0},
+		{2.0, -2.0, 4.0},
+		{-1.0, 0.5, -1.0},
+	}
+	b := []float64{1.0, -2.0, 0.0}
+	x, err := Solve(a, b)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := []float64{1.0, -2.0, -2.0}
+	for i := range want {
+		if math.Abs(x[i]-want[i]) > 1e-12 {
+			t.Errorf("at index %d expected %f, got %f", i, want[i], x[i])
+		}
+	}
+	_, err = Solve([][]float64{{1.0, 1.0}, {1.0, 1.0 + 1e-17}}, []float64{1.0, 2.0})
+	if err != ErrSingular {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+}
+
+func TestInverse(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{4.0, 7.0, 2.0},
+		{3.0, 6.0, 1.0},
+		{2.0, 5.0, 3.0},
+	}
+	inv, err := Inverse(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	id := Dot(m, inv)
+	for i := range id {
+		for j := range id[i] {
+			want := 0.0
+			if i == j {
+				want = 1.0
+			}
+			if math.Abs(id[i][j]-want) > 1e-12 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, want, id[i][j])
+			}
+		}
+	}
+	_, err = Inverse(New(3))
+	if err != ErrSingular {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+}
+
+func TestDet(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{6.0, 1.0, 1.0},
+		{4.0, -2.0, 5.0},
+		{2.0, 8.0, 7.0},
+	}
+	d := Det(m)
+	if math.Abs(d-(-306.0)) > 1e-10 {
+		t.Errorf("expected -306.0, got %f", d)
+	}
+	if d = Det(I(7)); d != 1.0 {
+		t.Errorf("expected 1.0, got %f", d)
+	}
+	if d = Det([][]float64{{1.0, 2.0}, {2.0, 4.0}}); d != 0.0 {
+		t.Errorf("expected 0.0, got %f", d)
+	}
+}
+
+func TestQR(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{12.0, -51.0, 4.0},
+		{6.0, 167.0, -68.0},
+		{-4.0, 24.0, -41.0},
+		{-1.0, 1.0, 0.0},
+	}
+	q, r := QR(m)
+	if len(q) != 4 || len(q[0]) != 3 || len(r) != 3 || len(r[0]) != 3 {
+		t.Fatalf("unexpected shapes %dx%d and %dx%d", len(q), len(q[0]), len(r), len(r[0]))
+	}
+	qtq := Dot(T(q), q)
+	for i := range qtq {
+		for j := range qtq[i] {
+			want := 0.0
+			if i == j {
+				want = 1.0
+			}
+			if math.Abs(qtq[i][j]-want) > 1e-12 {
+				t.Errorf("Q is not orthonormal at (%d, %d): %f", i, j, qtq[i][j])
+			}
+		}
+	}
+	qr := Dot(q, r)
+	for i := range qr {
+		for j := range qr[i] {
+			if math.Abs(qr[i][j]-m[i][j]) > 1e-10 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, m[i][j], qr[i][j])
+			}
+		}
+	}
+	for i := range r {
+		for j := 0; j < i; j++ {
+			if r[i][j] != 0.0 {
+				t.Errorf("r is not upper triangular at (%d, %d)", i, j)
+			}
+		}
+	}
+	if q, r = QR([][]float64{}); len(q) != 0 || len(r) != 0 {
+		t.Errorf("expected empty factors, got %v and %v", q, r)
+	}
+	if x, res, rank := LeastSquares([][]float64{}, []float64{}); len(x) != 0 || len(res) != 0 || rank != 0 {
+		t.Errorf("expected an empty solution, got %v, %v and rank %d", x, res, rank)
+	}
+}
+
+func TestLeastSquares(t *testing.T) {
+	t.Helper()
+	a := New(20, 2)
+	b := make([]float64, 20)
+	for i := range a {
+		a[i][0] = 1.0
+		a[i][1] = float64(i)
+		b[i] = 2.0 + 3.0*float64(i)
+	}
+	x, res, rank := LeastSquares(a, b)
+	if rank != 2 {
+		t.Errorf("expected rank 2, got %d", rank)
+	}
+	if math.Abs(x[0]-2.0) > 1e-10 || math.Abs(x[1]-3.0) > 1e-10 {
+		t.Errorf("expected [2 3], got %v", x)
+	}
+	for i := range res {
+		if math.Abs(res[i]) > 1e-10 {
+			t.Errorf("at index %d expected zero residual, got %g", i, res[i])
+		}
+	}
+	b[0] += 1.0
+	b[1] -= 1.0
+	x, res, _ = LeastSquares(a, b)
+	at := T(a)
+	for j := range at {
+		g := 0.0
+		for i := range res {
+			g += at[j][i] * res[i]
+		}
+		if math.Abs(g) > 1e-10 {
+			t.Errorf("residual is not orthogonal to column %d: %g", j, g)
+		}
+	}
+	AppendCol(a, Col(a, 1))
+	_, _, rank = LeastSquares(a, b)
+	if rank != 2 {
+		t.Errorf("expected rank 2 with a repeated column, got %d", rank)
+	}
+}
+
+func TestCholesky(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{4.0, 12.0, -16.0},
+		{12.0, 37.0, -43.0},
+		{-16.0, -43.0, 98.0},
+	}
+	l, err := Cholesky(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := [][]float64{
+		{2.0, 0.0, 0.0},
+		{6.0, 1.0, 0.0},
+		{-8.0, 5.0, 3.0},
+	}
+	if !Equal(l, want) {
+		t.Errorf("expected %v, got %v", want, l)
+	}
+	_, err = Cholesky([][]float64{{1.0, 2.0}, {2.0, 1.0}})
+	if err != ErrNotPositiveDefinite {
+		t.Errorf("expected ErrNotPositiveDefinite, got %v", err)
+	}
+}
+
+func TestCholeskySolve(t *testing.T) {
+	t.Helper()
+	a := [][]float64{
+		{4.0, 12.0, -16.0},
+		{12.0, 37.0, -43.0},
+		{-16.0, -43.0, 98.0},
+	}
+	b := []float64{1.0, 2.0, 3.0}
+	l, err := Cholesky(a)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	x := CholeskySolve(l, b)
+	y, _ := Solve(a, b)
+	for i := range x {
+		if math.Abs(x[i]-y[i]) > 1e-10 {
+			t.Errorf("at index %d expected %f, got %f", i, y[i], x[i])
+		}
+	}
+	if d := CholeskyLogDet(l); math.Abs(d-math.Log(Det(a))) > 1e-12 {
+		t.Errorf("expected %f, got %f", math.Log(Det(a)), d)
+	}
+}
+
+func TestSVD(t *testing.T) {
+	t.Helper()
+	for _, m := range [][][]float64{
+		{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}, {7.0, 8.0, 10.0}, {1.0, 0.0, 1.0}},
+		{{1.0, 2.0, 3.0, 4.0}, {2.0, 4.0, 6.0, 8.0}, {0.0, 1.0, 0.0, 1.0}},
+	} {
+		u, s, v, err := SVD(m)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(u) != len(m) || len(u[0]) != len(m) || len(v) != len(m[0]) || len(v[0]) != len(m[0]) {
+			t.Fatalf("unexpected shapes for u %dx%d and v %dx%d", len(u), len(u[0]), len(v), len(v[0]))
+		}
+		for _, q := range [][][]float64{u, v} {
+			qtq := Dot(T(q), q)
+			for i := range qtq {
+				for j := range qtq[i] {
+					want := 0.0
+					if i == j {
+						want = 1.0
+					}
+					if math.Abs(qtq[i][j]-want) > 1e-12 {
+						t.Errorf("not orthogonal at (%d, %d): %f", i, j, qtq[i][j])
+					}
+				}
+			}
+		}
+		for i := 1; i < len(s); i++ {
+			if s[i] > s[i-1] {
+				t.Errorf("singular values are not sorted: %v", s)
+			}
+		}
+		sm := New(len(m), len(m[0]))
+		for i := range s {
+			sm[i][i] = s[i]
+		}
+		usv := Dot(Dot(u, sm), T(v))
+		for i := range usv {
+			for j := range usv[i] {
+				if math.Abs(usv[i][j]-m[i][j]) > 1e-10 {
+					t.Errorf("at (%d, %d) expected %f, got %f", i, j, m[i][j], usv[i][j])
+				}
+			}
+		}
+	}
+	u, s, v, _ := ThinSVD(New(5, 2))
+	if len(u) != 5 || len(u[0]) != 2 || len(s) != 2 || len(v) != 2 || len(v[0]) != 2 {
+		t.Errorf("unexpected thin shapes")
+	}
+	for _, f := range []func([][]float64) ([][]float64, []float64, [][]float64, error){SVD, ThinSVD} {
+		if u, s, v, err := f([][]float64{}); err != nil || len(u) != 0 || len(s) != 0 || len(v) != 0 {
+			t.Errorf("expected empty factors, got %v, %v, %v (%v)", u, s, v, err)
+		}
+	}
+	if r, err := Rank([][]float64{}); err != nil || r != 0 {
+		t.Errorf("expected rank 0, got %d (%v)", r, err)
+	}
+}
+
+func TestPInv(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{4.0, 7.0}, {2.0, 6.0}}
+	p, err := PInv(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	inv, _ := Inverse(m)
+	for i := range p {
+		for j := range p[i] {
+			if math.Abs(p[i][j]-inv[i][j]) > 1e-12 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, inv[i][j], p[i][j])
+			}
+		}
+	}
+	m = [][]float64{{1.0, 2.0}, {2.0, 4.0}, {3.0, 6.0}}
+	p, _ = PInv(m)
+	mpm := Dot(Dot(m, p), m)
+	for i := range mpm {
+		for j := range mpm[i] {
+			if math.Abs(mpm[i][j]-m[i][j]) > 1e-12 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, m[i][j], mpm[i][j])
+			}
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	t.Helper()
+	if r, _ := Rank(I(6)); r != 6 {
+		t.Errorf("expected 6, got %d", r)
+	}
+	m := [][]float64{{1.0, 2.0, 3.0}, {2.0, 4.0, 6.0}, {1.0, 0.0, 1.0}}
+	if r, _ := Rank(m); r != 2 {
+		t.Errorf("expected 2, got %d", r)
+	}
+	if r, _ := Rank(m, 100.0); r != 0 {
+		t.Errorf("expected 0, got %d", r)
+	}
+}
+
+func TestCond(t *testing.T) {
+	t.Helper()
+	c, err := Cond([][]float64{{1.0, 0.0}, {0.0, 10.0}})
+	if err != nil || math.Abs(c-10.0) > 1e-12 {
+		t.Errorf("expected 10.0, got %f (%v)", c, err)
+	}
+	if c, _ = Cond(New(3)); !math.IsInf(c, 1) {
+		t.Errorf("expected +Inf, got %f", c)
+	}
+}
+
+func TestNullSpace(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 1.0, 0.0}, {2.0, 2.0, 0.0}}
+	ns, err := NullSpace(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(ns) != 3 || len(ns[0]) != 2 {
+		t.Fatalf("expected a 3x2 basis, got %dx%d", len(ns), len(ns[0]))
+	}
+	z := Dot(m, ns)
+	for i := range z {
+		for j := range z[i] {
+			if math.Abs(z[i][j]) > 1e-12 {
+				t.Errorf("at (%d, %d) expected 0.0, got %g", i, j, z[i][j])
+			}
+		}
+	}
+}
+
+func TestEigSym(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{2.0, -1.0, 0.0, 0.0},
+		{-1.0, 2.0, -1.0, 0.0},
+		{0.0, -1.0, 2.0, -1.0},
+		{0.0, 0.0, -1.0, 2.0},
+	}
+	vals, vecs, err := EigSym(m)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for k := range vals {
+		want := 2.0 - 2.0*math.Cos(float64(k+1)*math.Pi/5.0)
+		if math.Abs(vals[k]-want) > 1e-12 {
+			t.Errorf("eigenvalue %d expected %f, got %f", k, want, vals[k])
+		}
+	}
+	mv := Dot(m, vecs)
+	for i := range mv {
+		for j := range mv[i] {
+			if math.Abs(mv[i][j]-vals[j]*vecs[i][j]) > 1e-12 {
+				t.Errorf("at (%d, %d) expected %f, got %f", i, j, vals[j]*vecs[i][j], mv[i][j])
+			}
+		}
+	}
+	vtv := Dot(T(vecs), vecs)
+	for i := range vtv {
+		for j := range vtv[i] {
+			want := 0.0
+			if i == j {
+				want = 1.0
+			}
+			if math.Abs(vtv[i][j]-want) > 1e-12 {
+				t.Errorf("not orthonormal at (%d, %d): %f", i, j, vtv[i][j])
+			}
+		}
+	}
+}
+
+func TestEig(t *testing.T) {
+	t.Helper()
+	for _, m := range [][][]float64{
+		{{0.0, -1.0}, {1.0, 0.0}},
+		{{6.0, -11.0, 6.0}, {1.0, 0.0, 0.0}, {0.0, 1.0, 0.0}},
+		{{4.0, -2.0, 1.0, 3.0}, {3.0, 6.0, -4.0, 2.0}, {2.0, 1.0, 8.0, -5.0}, {1.0, 7.0, 2.0, 1.0}},
+		{{1.0, 1.0}, {0.0, 1.0}},
+	} {
+		vals, vecs, err := Eig(m)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for k := range vals {
+			for i := range m {
+				var av complex128
+				for j := range m[i] {
+					av += complex(m[i][j], 0.0) * vecs[j][k]
+				}
+				if d := av - vals[k]*vecs[i][k]; math.Hypot(real(d), imag(d)) > 1e-10 {
+					t.Errorf("eigenpair %d of %v fails at row %d: %v", k, m, i, d)
+				}
+			}
+		}
+		vs, err := EigVals(m)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		for k := range vs {
+			if vs[k] != vals[k] {
+				t.Errorf("EigVals expected %v, got %v", vals[k], vs[k])
+			}
+		}
+	}
+	vals, _, _ := Eig([][]float64{{0.0, -1.0}, {1.0, 0.0}})
+	if vals[0] != complex(0.0, 1.0) || vals[1] != complex(0.0, -1.0) {
+		t.Errorf("expected [i, -i], got %v", vals)
+	}
+	vals, _ = EigVals([][]float64{{6.0, -11.0, 6.0}, {1.0, 0.0, 0.0}, {0.0, 1.0, 0.0}})
+	for _, want := range []float64{1.0, 2.0, 3.0} {
+		found := false
+		for _, v := range vals {
+			if math.Abs(real(v)-want) < 1e-10 && imag(v) == 0.0 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected eigenvalue %f in %v", want, vals)
+		}
+	}
+}
+
+func TestTry(t *testing.T) {
+	t.Helper()
+	if _, err := TryNew(1, 2, 3); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if _, err := TryNew(-1); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if m, err := TryNew(2, 3); err != nil || len(m) != 2 || len(m[0]) != 3 {
+		t.Errorf("expected a 2x3 [][]float64, got %v (%v)", m, err)
+	}
+	_, err := TryDot(New(2, 3), New(4, 2))
+	var se *ShapeError
+	if !errors.As(err, &se) || !errors.Is(err, ErrShapeMismatch) {
+		t.Fatalf("expected a ShapeError, got %v", err)
+	}
+	if se.Rows != 4 || se.Cols != 2 || se.WantRows != 3 {
+		t.Errorf("unexpected dimensions in %v", se)
+	}
+	jagged := [][]float64{{1.0, 2.0}, {3.0, 4.0}, {5.0}}
+	_, err = TrySum(jagged, 1, 1)
+	var je *JaggedError
+	if !errors.As(err, &je) || je.Row != 2 || je.Len != 1 || je.Want != 2 {
+		t.Errorf("expected a JaggedError for row 2, got %v", err)
+	}
+	if _, err = TrySum(New(2, 3), 1, -4); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err = TrySum(New(2, 3), 2, 0); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if s, err := TrySum(I(3), 0, -1); err != nil || s != 1.0 {
+		t.Errorf("expected 1.0, got %f (%v)", s, err)
+	}
+	m := I(2)
+	if err = TryMult(m, []float64{1.0, 2.0, 3.0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if err = TryAdd(m, "1"); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if err = TrySub(m, I(2)); err != nil || !Equal(m, New(2)) {
+		t.Errorf("expected a zero [][]float64, got %v (%v)", m, err)
+	}
+	if _, err = TryCol(m, 2); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Errorf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if err = TryAppendCol(m, []float64{1.0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if err = TrySet(3.0, 1.0); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestTryLinearAlgebra(t *testing.T) {
+	t.Helper()
+	wide := New(2, 3)
+	jagged := [][]float64{{1.0, 2.0}, {3.0}}
+	b := []float64{1.0, 2.0}
+	errs := map[string]error{}
+	_, _, _, errs["LU"] = TryLU(wide)
+	_, errs["Solve"] = TrySolve(wide, b)
+	_, errs["Inverse"] = TryInverse(wide)
+	_, errs["Det"] = TryDet(wide)
+	_, errs["Cholesky"] = TryCholesky(wide)
+	_, errs["CholeskySolve"] = TryCholeskySolve(wide, b)
+	_, _, errs["EigSym"] = TryEigSym(wide)
+	_, _, errs["Eig"] = TryEig(wide)
+	_, errs["EigVals"] = TryEigVals(wide)
+	for name, err := range errs {
+		var se *ShapeError
+		if !errors.As(err, &se) || se.Rows != 2 || se.Cols != 3 || se.WantCols != 2 {
+			t.Errorf("%s: expected a ShapeError for a 2x3 [][]float64, got %v", name, err)
+		}
+	}
+	if _, err := TryInverse(jagged); !errors.Is(err, ErrJagged) {
+		t.Errorf("expected ErrJagged, got %v", err)
+	}
+	a := [][]float64{{2.0, 0.0}, {0.0, 4.0}}
+	if _, err := TrySolve(a, []float64{1.0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if _, err := TryCholeskySolve(a, []float64{1.0, 2.0, 3.0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if _, _, _, err := TryLeastSquares(wide, []float64{1.0}); !errors.Is(err, ErrShapeMismatch) {
+		t.Errorf("expected ErrShapeMismatch, got %v", err)
+	}
+	if _, _, _, err := TryLeastSquares(jagged, b); !errors.Is(err, ErrJagged) {
+		t.Errorf("expected ErrJagged, got %v", err)
+	}
+	if x, err := TrySolve(a, []float64{2.0, 8.0}); err != nil || x[0] != 1.0 || x[1] != 2.0 {
+		t.Errorf("expected [1 2], got %v (%v)", x, err)
+	}
+	if d, err := TryDet(a); err != nil || d != 8.0 {
+		t.Errorf("expected 8.0, got %v (%v)", d, err)
+	}
+	if _, err := TrySolve([][]float64{{1.0, 2.0}, {2.0, 4.0}}, b); !errors.Is(err, ErrSingular) {
+		t.Errorf("expected ErrSingular, got %v", err)
+	}
+	errs = map[string]error{}
+	_, _, errs["QR"] = TryQR([][]float64{{1.0, 2.0}, {1.0}})
+	_, _, _, errs["SVD"] = TrySVD([][]float64{{1.0}, {1.0, 2.0}})
+	_, _, _, errs["ThinSVD"] = TryThinSVD(jagged)
+	_, errs["PInv"] = TryPInv(jagged)
+	_, errs["Rank"] = TryRank(jagged)
+	_, errs["Cond"] = TryCond(jagged)
+	_, errs["NullSpace"] = TryNullSpace(jagged)
+	for name, err := range errs {
+		if !errors.Is(err, ErrJagged) {
+			t.Errorf("%s: expected ErrJagged, got %v", name, err)
+		}
+	}
+	errs = map[string]error{}
+	_, errs["PInv"] = TryPInv(a, 1e-10, 1e-12)
+	_, errs["Rank"] = TryRank(a, 1e-10, 1e-12)
+	_, errs["NullSpace"] = TryNullSpace(a, 1e-10, 1e-12)
+	for name, err := range errs {
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("%s: expected ErrInvalidArgument, got %v", name, err)
+		}
+	}
+	if r, err := TryRank([][]float64{{1.0, 2.0}, {2.0, 4.0}}); err != nil || r != 1 {
+		t.Errorf("expected rank 1, got %d (%v)", r, err)
+	}
+	if c, err := TryCond(a); err != nil || math.Abs(c-2.0) > 1e-12 {
+		t.Errorf("expected 2.0, got %v (%v)", c, err)
+	}
+	if q, r, err := TryQR(a); err != nil || !EqualApprox(Dot(q, r), a, Tol{Abs: 1e-12}) {
+		t.Errorf("expected q * r to be %v, got %v (%v)", a, Dot(q, r), err)
+	}
+}
+
+func TestShape(t *testing.T) {
+	t.Helper()
+	rows, cols, err := Shape(New(4, 7))
+	if rows != 4 || cols != 7 || err != nil {
+		t.Errorf("expected 4x7, got %dx%d (%v)", rows, cols, err)
+	}
+	jagged := [][]float64{{1.0, 2.0}, {3.0, 4.0}, {5.0, 6.0, 7.0}}
+	_, _, err = Shape(jagged)
+	var je *JaggedError
+	if !errors.As(err, &je) || je.Row != 2 || je.Len != 3 || je.Want != 2 {
+		t.Errorf("expected a JaggedError for row 2, got %v", err)
+	}
+	if !IsRect(New(3, 2)) || IsRect(jagged) {
+		t.Errorf("IsRect does not detect jagged [][]float64s")
+	}
+}
+
+func TestCheckedMode(t *testing.T) {
+	t.Helper()
+	if !debug {
+		t.Skip("checked mode is only enabled with the matf64debug build tag")
+	}
+	defer func() {
+		err, _ := recover().(error)
+		var je *JaggedError
+		if !errors.As(err, &je) || je.Row != 1 {
+			t.Errorf("expected a JaggedError for row 1, got %v", err)
+		}
+	}()
+	T([][]float64{{1.0, 2.0}, {3.0}})
+}
+
+func TestEqualApprox(t *testing.T) {
+	t.Helper()
+	a, b := 0.1, 0.2
+	m := [][]float64{{a + b, 1.0}, {math.NaN(), math.Inf(1)}}
+	n := [][]float64{{0.3, 1.0}, {math.NaN(), math.Inf(1)}}
+	if EqualApprox(m, n, Tol{Abs: 1e-12}) {
+		t.Errorf("NaN should not equal NaN unless NaNEqual is set")
+	}
+	if !EqualApprox(m, n, Tol{Abs: 1e-12, NaNEqual: true}) {
+		t.Errorf("expected equal within an absolute tolerance")
+	}
+	if !EqualApprox(m, n, Tol{Rel: 1e-15, NaNEqual: true}) {
+		t.Errorf("expected equal within a relative tolerance")
+	}
+	if !EqualApprox(m, n, Tol{ULP: 1, NaNEqual: true}) {
+		t.Errorf("expected equal within 1 ULP")
+	}
+	if EqualApprox(m, n, Tol{NaNEqual: true}) {
+		t.Errorf("expected the zero tolerance to be exact")
+	}
+	if !(Tol{ULP: 2}).Equal(-0.0, math.Nextafter(0.0, 1.0)) {
+		t.Errorf("expected -0.0 and the smallest denormal to be 1 ULP apart")
+	}
+	if (Tol{Abs: math.Inf(1)}).Equal(math.Inf(1), math.Inf(-1)) {
+		t.Errorf("opposite infinities should never be equal")
+	}
+	if EqualApprox(New(2, 3), New(3, 2), Tol{Abs: 1.0}) {
+		t.Errorf("different shapes should not be equal")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Helper()
+	got := [][]float64{{1.0, 2.0}, {3.0, 4.5}}
+	want := [][]float64{{1.0, 2.0 + 1e-14}, {3.0, 4.0}, {5.0, 6.0}}
+	d := Diff(got, want, Tol{Abs: 1e-12})
+	if len(d) != 3 {
+		t.Fatalf("expected 3 mismatches, got %v", d)
+	}
+	if d[0].Row != 1 || d[0].Col != 1 || d[0].Got != 4.5 || d[0].Want != 4.0 {
+		t.Errorf("unexpected mismatch %v", d[0])
+	}
+	if d[1].Row != 2 || !math.IsNaN(d[1].Got) || d[1].Want != 5.0 {
+		t.Errorf("unexpected mismatch %v", d[1])
+	}
+	if s := d[0].String(); s != "(1, 1): got 4.5, want 4" {
+		t.Errorf("unexpected string %q", s)
+	}
+	if d = Diff(got, got, Tol{}); d != nil {
+		t.Errorf("expected no mismatches, got %v", d)
+	}
+}
+
+func TestAxisReductions(t *testing.T) {
+	t.Helper()
+	m := [][]float64{
+		{1.0, 2.0, 3.0},
+		{4.0, -5.0, 6.0},
+	}
+	cases := []struct {
+		name string
+		f    AxisReducerFn
+		rows []float64
+		cols []float64
+	}{
+		{"SumAxis", SumAxis, []float64{6.0, 5.0}, []float64{5.0, -3.0, 9.0}},
+		{"ProdAxis", ProdAxis, []float64{6.0, -120.0}, []float64{4.0, -10.0, 18.0}},
+		{"AvgAxis", AvgAxis, []float64{2.0, 5.0 / 3.0}, []float64{2.5, -1.5, 4.5}},
+		{"MinAxis", MinAxis, []float64{1.0, -5.0}, []float64{1.0, -5.0, 3.0}},
+		{"MaxAxis", MaxAxis, []float64{3.0, 6.0}, []float64{4.0, 2.0, 6.0}},
+		{"NewAxisReducer", NewAxisReducer(0, func(i *float64, j *float64) {
+			*i += *j * *j
+		}), []float64{14.0, 77.0}, []float64{17.0, 29.0, 45.0}},
+	}
+	for _, c := range cases {
+		if got := c.f(m, 0); !EqualApprox([][]float64{got}, [][]float64{c.rows}, Tol{Abs: 1e-15}) {
+			t.Errorf("%s over rows expected %v, got %v", c.name, c.rows, got)
+		}
+		if got := c.f(m, 1); !EqualApprox([][]float64{got}, [][]float64{c.cols}, Tol{Abs: 1e-15}) {
+			t.Errorf("%s over columns expected %v, got %v", c.name, c.cols, got)
+		}
+	}
+	for j := range m[0] {
+		if s := SumAxis(m, 1)[j]; s != Sum(m, 1, j) {
+			t.Errorf("at col %d expected %f, got %f", j, Sum(m, 1, j), s)
+		}
+	}
+	defer func() {
+		r := recover()
+		if s, ok := r.(string); !ok || !strings.Contains(s, "AvgAxis()") {
+			t.Errorf("expected a panic naming AvgAxis(), got %v", r)
+		}
+	}()
+	AvgAxis(m, 2)
+}
+
+func TestVar(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{2.0, 4.0, 4.0, 4.0}, {5.0, 5.0, 7.0, 9.0}}
+	if v := Var(m); v != 4.0 {
+		t.Errorf("expected 4.0, got %f", v)
+	}
+	if v := Std(m); v != 2.0 {
+		t.Errorf("expected 2.0, got %f", v)
+	}
+	if v := SampleVar(m); math.Abs(v-32.0/7.0) > 1e-15 {
+		t.Errorf("expected %f, got %f", 32.0/7.0, v)
+	}
+	if v := SampleStd(m); math.Abs(v-math.Sqrt(32.0/7.0)) > 1e-15 {
+		t.Errorf("expected %f, got %f", math.Sqrt(32.0/7.0), v)
+	}
+	if v := Var(m, 0, 0); v != 0.75 {
+		t.Errorf("expected 0.75, got %f", v)
+	}
+	if v := SampleVar(m, 1, -1); v != 12.5 {
+		t.Errorf("expected 12.5, got %f", v)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{4.0, 1.0}, {3.0, 2.0}}
+	if v := Median(m); v != 2.5 {
+		t.Errorf("expected 2.5, got %f", v)
+	}
+	if v := Median(m, 0, 0); v != 2.5 {
+		t.Errorf("expected 2.5, got %f", v)
+	}
+	if v := Median([][]float64{{3.0, 1.0, 2.0}}); v != 2.0 {
+		t.Errorf("expected 2.0, got %f", v)
+	}
+	for _, c := range []struct {
+		method Interpolation
+		want   float64
+	}{
+		{Linear, 1.3}, {Lower, 1.0}, {Higher, 2.0}, {Nearest, 1.0}, {Midpoint, 1.5},
+	} {
+		if v := Quantile(m, 0.1, c.method); math.Abs(v-c.want) > 1e-15 {
+			t.Errorf("method %d expected %f, got %f", c.method, c.want, v)
+		}
+	}
+	if v := Percentile(m, 100.0, Linear); v != 4.0 {
+		t.Errorf("expected 4.0, got %f", v)
+	}
+	if v := Quantile(m, 1.0/3.0, Linear, 1, 1); math.Abs(v-4.0/3.0) > 1e-15 {
+		t.Errorf("expected %f, got %f", 4.0/3.0, v)
+	}
+	if !Equal(m, [][]float64{{4.0, 1.0}, {3.0, 2.0}}) {
+		t.Errorf("Quantile mutated its input")
+	}
+	inf := [][]float64{{1.0, 2.0, math.Inf(1)}, {math.Inf(-1), 0.0, 3.0}}
+	for _, method := range []Interpolation{Linear, Lower, Higher, Nearest, Midpoint} {
+		if v := Quantile(inf, 1.0, method); !math.IsInf(v, 1) {
+			t.Errorf("method %d expected +Inf, got %f", method, v)
+		}
+		if v := Quantile(inf, 0.0, method); !math.IsInf(v, -1) {
+			t.Errorf("method %d expected -Inf, got %f", method, v)
+		}
+	}
+	if v := Quantile(inf, 0.9, Linear); !math.IsInf(v, 1) {
+		t.Errorf("expected +Inf, got %f", v)
+	}
+	if v := Quantile([][]float64{{math.Inf(-1), 1.0}}, 0.5, Linear); !math.IsInf(v, -1) {
+		t.Errorf("expected -Inf, got %f", v)
+	}
+	if v := Quantile([][]float64{{math.Inf(-1), 1.0}}, 0.5, Midpoint); !math.IsInf(v, -1) {
+		t.Errorf("expected -Inf, got %f", v)
+	}
+	if v := Quantile([][]float64{{-1.5e308, 1.7e308}}, 0.75, Linear); math.Abs(v-0.9e308) > 1e293 {
+		t.Errorf("expected 0.9e308, got %g", v)
+	}
+	if v := Median([][]float64{{1.7e308}}); v != 1.7e308 {
+		t.Errorf("expected 1.7e308, got %g", v)
+	}
+	if v := Median([][]float64{{1.7e308, 1.5e308}}); v != 1.6e308 {
+		t.Errorf("expected 1.6e308, got %g", v)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{3.0, -1.0, 7.0}, {7.0, 0.0, -4.0}}
+	if v := Min(m); v != -4.0 {
+		t.Errorf("expected -4.0, got %f", v)
+	}
+	if v := Max(m, 0, 0); v != 7.0 {
+		t.Errorf("expected 7.0, got %f", v)
+	}
+	if i, j := ArgMin(m); i != 1 || j != 2 {
+		t.Errorf("expected (1, 2), got (%d, %d)", i, j)
+	}
+	if i, j := ArgMax(m); i != 0 || j != 2 {
+		t.Errorf("expected (0, 2), got (%d, %d)", i, j)
+	}
+	if i, j := ArgMin(m, 1, 1); i != 0 || j != 1 {
+		t.Errorf("expected (0, 1), got (%d, %d)", i, j)
+	}
+	if i, j := ArgMax(m, 0, -1); i != 1 || j != 0 {
+		t.Errorf("expected (1, 0), got (%d, %d)", i, j)
+	}
+	m[1][1] = math.NaN()
+	if v := Min(m); !math.IsNaN(v) {
+		t.Errorf("expected NaN, got %f", v)
+	}
+	if i, j := ArgMax(m); i != 1 || j != 1 {
+		t.Errorf("expected the NaN at (1, 1), got (%d, %d)", i, j)
+	}
+}
+
+func TestMode(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 2.0}, {3.0, 3.0, 1.0}}
+	if v := Mode(m); v != 1.0 {
+		t.Errorf("expected 1.0, got %f", v)
+	}
+	if v := Mode(m, 0, 0); v != 2.0 {
+		t.Errorf("expected 2.0, got %f", v)
+	}
+	if v := Mode(m, 1, 1); v != 2.0 {
+		t.Errorf("expected 2.0, got %f", v)
+	}
+}
+
+func TestCov(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 3.0}, {2.0, 4.0, 1.0}, {3.0, 6.0, 2.0}}
+	want := [][]float64{{1.0, 2.0, -0.5}, {2.0, 4.0, -1.0}, {-0.5, -1.0, 1.0}}
+	if d := Diff(Cov(m), want, Tol{Abs: 1e-15}); d != nil {
+		t.Errorf("unexpected covariance entries %v", d)
+	}
+	if d := Diff(CovWeighted(m, []float64{2.0, 2.0, 2.0}), want, Tol{Abs: 1e-15}); d != nil {
+		t.Errorf("equal weights should match Cov, got %v", d)
+	}
+	w := []float64{1.0, 0.0, 1.0}
+	cw := CovWeighted(m, w)
+	c2 := Cov([][]float64{m[0], m[2]})
+	if d := Diff(cw, c2, Tol{Abs: 1e-15}); d != nil {
+		t.Errorf("a zero weight should drop the row, got %v", d)
+	}
+}
+
+func TestCorr(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 1.0}, {2.0, 4.0, 8.0}, {3.0, 6.0, 27.0}, {4.0, 8.0, 64.0}}
+	p := Corr(m, Pearson)
+	if math.Abs(p[0][1]-1.0) > 1e-15 || p[0][0] != 1.0 {
+		t.Errorf("expected perfect linear correlation, got %v", p)
+	}
+	if p[0][2] >= 1.0-1e-6 || p[0][2] != p[2][0] {
+		t.Errorf("expected an imperfect symmetric correlation, got %v", p[0][2])
+	}
+	s := Corr(m, Spearman)
+	for i := range s {
+		for j := range s[i] {
+			if math.Abs(s[i][j]-1.0) > 1e-15 {
+				t.Errorf("expected perfect rank correlation at (%d, %d), got %f", i, j, s[i][j])
+			}
+		}
+	}
+	r := rank([]float64{10.0, 20.0, 10.0, 5.0})
+	if !Equal([][]float64{r}, [][]float64{{2.5, 4.0, 2.5, 1.0}}) {
+		t.Errorf("unexpected ranks %v", r)
+	}
+}
+
+func exactSum(v []float64) float64 {
+	sum := new(big.Float).SetPrec(4096)
+	for i := range v {
+		sum.Add(sum, new(big.Float).SetFloat64(v[i]))
+	}
+	f, _ := sum.Float64()
+	return f
+}
+
+func TestSummation(t *testing.T) {
+	t.Helper()
+	v := []float64{1.0, 1e100, 1.0, -1e100}
+	if s := KahanSum.SumVec(v); s != 2.0 {
+		t.Errorf("expected 2.0, got %g", s)
+	}
+	for _, v := range [][]float64{{1.0, math.Inf(1)}, {math.Inf(-1), 2.0, 1e100}} {
+		if s := KahanSum.SumVec(v); !math.IsInf(s, 0) || s != NaiveSum.SumVec(v) {
+			t.Errorf("expected %g for %v, got %g", NaiveSum.SumVec(v), v, s)
+		}
+	}
+	rng := rand.New(rand.NewSource(7))
+	n := 1 << 20
+	m := New(1024, n/1024)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = (rng.Float64() - 0.5) * math.Pow(10.0, float64(rng.Intn(20)-10))
+		}
+	}
+	flat := Flatten(m)
+	exact := exactSum(flat)
+	absSum := 0.0
+	for i := range flat {
+		absSum += math.Abs(flat[i])
+	}
+	bounds := map[Summation]float64{
+		NaiveSum:    float64(n) * eps * absSum,
+		KahanSum:    2.0*eps*math.Abs(exact) + float64(n)*eps*eps*absSum,
+		PairwiseSum: (math.Log2(float64(n)) + pairwiseBlock) * eps * absSum,
+	}
+	for alg, bound := range bounds {
+		if err := math.Abs(SumWith(alg, m) - exact); err > bound {
+			t.Errorf("summation %d has error %g, above its bound %g", alg, err, bound)
+		}
+	}
+	if err := math.Abs(SumWith(KahanSum, m) - exact); err > math.Abs(SumWith(NaiveSum, m)-exact) {
+		t.Errorf("compensated summation is less accurate than naive summation")
+	}
+	col := Col(m, 3)
+	if s := SumWith(KahanSum, m, 1, 3); s != exactSum(col) {
+		t.Errorf("expected %g, got %g", exactSum(col), s)
+	}
+	if a := AvgWith(KahanSum, m, 1, 3); a != exactSum(col)/float64(len(col)) {
+		t.Errorf("expected %g, got %g", exactSum(col)/float64(len(col)), a)
+	}
+	sq := NewSumReducer(KahanSum, func(i *float64) { *i *= *i })
+	if s := sq([][]float64{{1.0, 2.0}, {3.0, 4.0}}); s != 30.0 {
+		t.Errorf("expected 30.0, got %f", s)
+	}
+}
+
+func TestDotWith(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 1e100, 1.0, -1e100}}
+	n := [][]float64{{1.0}, {1.0}, {1.0}, {1.0}}
+	if d := DotWith(KahanSum, m, n); d[0][0] != 2.0 {
+		t.Errorf("expected 2.0, got %g", d[0][0])
+	}
+	a := RandMat(5, 5)
+	if !Equal(DotWith(NaiveSum, a, a), Dot(a, a)) {
+		t.Errorf("expected NaiveSum to match Dot")
+	}
+}
+
+func TestLogProd(t *testing.T) {
+	t.Helper()
+	m := New(100, 100)
+	Set(m, 1e-5)
+	m[3][4] = -1e-5
+	if p := Prod(m); p != 0.0 {
+		t.Fatalf("expected Prod to underflow, got %g", p)
+	}
+	logAbs, sign := LogProd(m)
+	if want := 1e4 * math.Log(1e-5); math.Abs(logAbs-want) > 1e-9*math.Abs(want) || sign != -1.0 {
+		t.Errorf("expected (%f, -1), got (%f, %f)", want, logAbs, sign)
+	}
+	logAbs, sign = LogProd([][]float64{{2.0, -3.0}, {0.0, 4.0}}, 0, 0)
+	if math.Abs(logAbs-math.Log(6.0)) > 1e-15 || sign != -1.0 {
+		t.Errorf("expected (%f, -1), got (%f, %f)", math.Log(6.0), logAbs, sign)
+	}
+	logAbs, sign = LogProd([][]float64{{2.0, -3.0}, {0.0, 4.0}}, 1, 0)
+	if !math.IsInf(logAbs, -1) || sign != 0.0 {
+		t.Errorf("expected (-Inf, 0), got (%f, %f)", logAbs, sign)
+	}
+	la, s := LogProdAxis([][]float64{{2.0, -3.0}, {0.5, 4.0}}, 1)
+	if math.Abs(la[0]) > 1e-15 || s[0] != 1.0 || math.Abs(la[1]-math.Log(12.0)) > 1e-15 || s[1] != -1.0 {
+		t.Errorf("unexpected column products %v, %v", la, s)
+	}
+}
+
+func TestLogSumExp(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1000.0, 1000.0}, {-1000.0, math.Inf(-1)}}
+	if v := LogSumExp(m, 0, 0); math.Abs(v-(1000.0+math.Log(2.0))) > 1e-12 {
+		t.Errorf("expected %f, got %f", 1000.0+math.Log(2.0), v)
+	}
+	if v := LogSumExp(m, 0, 1); v != -1000.0 {
+		t.Errorf("expected -1000.0, got %f", v)
+	}
+	if v := LogSumExp([][]float64{{math.Inf(-1)}}); !math.IsInf(v, -1) {
+		t.Errorf("expected -Inf, got %f", v)
+	}
+	v := LogSumExpAxis([][]float64{{0.0, math.Log(3.0)}, {0.0, 0.0}}, 0)
+	if math.Abs(v[0]-math.Log(4.0)) > 1e-15 || math.Abs(v[1]-math.Log(2.0)) > 1e-15 {
+		t.Errorf("unexpected row results %v", v)
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Helper()
+	in := "# measurements\na\tb\tc\n1\t2.5\t-3e2\n4\t\tInf\n"
+	m, header, err := ReadCSV(strings.NewReader(in), CSVOptions{
+		Comma: '\t', Comment: '#', Header: true, EmptyAsNaN: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(header) != 3 || header[0] != "a" || header[2] != "c" {
+		t.Errorf("unexpected header %v", header)
+	}
+	want := [][]float64{{1.0, 2.5, -300.0}, {4.0, math.NaN(), math.Inf(1)}}
+	if !EqualApprox(m, want, Tol{NaNEqual: true}) {
+		t.Errorf("expected %v, got %v", want, m)
+	}
+	_, _, err = ReadCSV(strings.NewReader("1,2\n3, x\n"), CSVOptions{})
+	var ce *CSVError
+	if !errors.As(err, &ce) || ce.Line != 2 || ce.Column != 3 || ce.Field != 1 {
+		t.Errorf("expected an error at line 2, column 3, got %v", err)
+	}
+	if _, _, err = ReadCSV(strings.NewReader("1,\n"), CSVOptions{}); !errors.As(err, &ce) {
+		t.Errorf("expected empty cells to be an error, got %v", err)
+	}
+	if _, _, err = ReadCSV(strings.NewReader("1,2\n3\n"), CSVOptions{}); err == nil {
+		t.Errorf("expected an error for a short record")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{0.1, -2.0, math.NaN()}, {1e300, 5.0, 6.0}}
+	var buf bytes.Buffer
+	opts := CSVOptions{Comma: ';', EmptyAsNaN: true, Header: true}
+	if err := WriteCSV(&buf, m, []string{"x", "y", "z"}, opts); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if s := buf.String(); s != "x;y;z\n0.1;-2;\n1e+300;5;6\n" {
+		t.Errorf("unexpected output %q", s)
+	}
+	n, header, err := ReadCSV(&buf, opts)
+	if err != nil || len(header) != 3 || !EqualApprox(m, n, Tol{NaNEqual: true}) {
+		t.Errorf("round trip failed, got %v, %v (%v)", n, header, err)
+	}
+	buf.Reset()
+	m = [][]float64{{1.0}, {math.NaN()}, {3.0}}
+	opts = CSVOptions{EmptyAsNaN: true}
+	if err = WriteCSV(&buf, m, nil, opts); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if s := buf.String(); s != "1\n\"\"\n3\n" {
+		t.Errorf("unexpected output %q", s)
+	}
+	n, _, err = ReadCSV(&buf, opts)
+	if err != nil || !EqualApprox(m, n, Tol{NaNEqual: true}) {
+		t.Errorf("round trip of a single column failed, got %v (%v)", n, err)
+	}
+}
+
+func TestNPY(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 3.0}, {4.0, math.Inf(-1), 0.1}}
+	v := []float64{0.5, -1.5}
+	for _, opts := range []NPYOptions{{}, {FortranOrder: true}, {BigEndian: true}, {true, true}} {
+		var buf bytes.Buffer
+		if err := WriteNPY(&buf, m, opts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if (buf.Len()-8*6)%64 != 0 {
+			t.Errorf("expected the data to be aligned to 64 bytes, got a length of %d", buf.Len())
+		}
+		a, err := ReadNPY(&buf)
+		if n, ok := a.([][]float64); err != nil || !ok || !Equal(m, n) {
+			t.Errorf("round trip with %+v failed, got %v (%v)", opts, a, err)
+		}
+		buf.Reset()
+		if err := WriteNPY(&buf, v, opts); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		a, err = ReadNPY(&buf)
+		if u, ok := a.([]float64); err != nil || !ok || len(u) != 2 || u[0] != 0.5 || u[1] != -1.5 {
+			t.Errorf("round trip with %+v failed, got %v (%v)", opts, a, err)
+		}
+	}
+	header := "{'descr': '>f8', 'fortran_order': True, 'shape': (2, 2), }"
+	data := npyMagic + "\x01\x00" + string([]byte{byte(len(header) + 1), 0}) + header + "\n"
+	for _, x := range []uint64{math.Float64bits(1.0), math.Float64bits(3.0), math.Float64bits(2.0), math.Float64bits(4.0)} {
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], x)
+		data += string(b[:])
+	}
+	a, err := ReadNPY(strings.NewReader(data))
+	if n, ok := a.([][]float64); err != nil || !ok || !Equal(n, [][]float64{{1.0, 2.0}, {3.0, 4.0}}) {
+		t.Errorf("expected [[1 2] [3 4]], got %v (%v)", a, err)
+	}
+	if _, err = ReadNPY(strings.NewReader(data[:len(data)-3])); err != io.ErrUnexpectedEOF {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+	bad := strings.Replace(data, ">f8", "<i8", 1)
+	if _, err = ReadNPY(strings.NewReader(bad)); !errors.Is(err, ErrFormat) {
+		t.Errorf("expected ErrFormat for an int64 array, got %v", err)
+	}
+	npy := func(shape string) string {
+		header := "{'descr': '<f8', 'fortran_order': False, 'shape': " + shape + ", }"
+		return npyMagic + "\x01\x00" + string([]byte{byte(len(header) + 1), 0}) + header + "\n" + "\x00\x00\x00\x00\x00\x00\xf0\x3f"
+	}
+	for _, shape := range []string{"(1152921504606846976,)", "(4294967296, 4294967296)", "(-1,)", "(1073741824, 0)", "(65537, 0)"} {
+		if _, err = ReadNPY(strings.NewReader(npy(shape))); !errors.Is(err, ErrFormat) {
+			t.Errorf("expected ErrFormat for the shape %s, got %v", shape, err)
+		}
+	}
+	if a, err = ReadNPY(strings.NewReader(npy("(3, 0)"))); err != nil || len(a.([][]float64)) != 3 {
+		t.Errorf("expected 3 empty rows, got %v (%v)", a, err)
+	}
+	for _, shape := range []string{"(100000000,)", "(100000, 1000)"} {
+		if _, err = ReadNPY(strings.NewReader(npy(shape))); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected io.ErrUnexpectedEOF for the shape %s, got %v", shape, err)
+		}
+	}
+}
+
+func TestNPZ(t *testing.T) {
+	t.Helper()
+	arrays := map[string]interface{}{
+		"weights": [][]float64{{1.0, 2.0}, {3.0, 4.0}, {5.0, 6.0}},
+		"bias":    []float64{-1.0, 1.0},
+	}
+	var buf bytes.Buffer
+	if err := WriteNPZ(&buf, arrays, NPYOptions{}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	got, err := ReadNPZ(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil || len(got) != 2 {
+		t.Fatalf("expected 2 arrays, got %v (%v)", got, err)
+	}
+	if w, ok := got["weights"].([][]float64); !ok || !Equal(w, arrays["weights"].([][]float64)) {
+		t.Errorf("expected %v, got %v", arrays["weights"], got["weights"])
+	}
+	if b, ok := got["bias"].([]float64); !ok || len(b) != 2 || b[0] != -1.0 {
+		t.Errorf("expected %v, got %v", arrays["bias"], got["bias"])
+	}
+	if err = WriteNPZ(&buf, map[string]interface{}{"x": 1.0}, NPYOptions{}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestReadMatrixMarket(t *testing.T) {
+	t.Helper()
+	in := "%%MatrixMarket matrix coordinate real symmetric\n% a test matrix\n\n3 3 4\n1 1 2.0\n2 1 -1\n3 2 -1.5e0\n3 3 4\n"
+	m, h, err := ReadMatrixMarket(strings.NewReader(in))
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := [][]float64{{2.0, -1.0, 0.0}, {-1.0, 0.0, -1.5}, {0.0, -1.5, 4.0}}
+	if !Equal(m, want) {
+		t.Errorf("expected %v, got %v", want, m)
+	}
+	if h.Format != "coordinate" || h.Symmetry != "symmetric" || len(h.Comments) != 1 || h.Comments[0] != " a test matrix" {
+		t.Errorf("unexpected header %+v", h)
+	}
+	in = "%%MatrixMarket matrix array integer skew-symmetric\n3 3\n1\n2\n3\n"
+	m, _, err = ReadMatrixMarket(strings.NewReader(in))
+	want = [][]float64{{0.0, -1.0, -2.0}, {1.0, 0.0, -3.0}, {2.0, 3.0, 0.0}}
+	if err != nil || !Equal(m, want) {
+		t.Errorf("expected %v, got %v (%v)", want, m, err)
+	}
+	m, _, err = ReadMatrixMarket(strings.NewReader("%%MatrixMarket matrix array real general\n3 0\n"))
+	if err != nil || len(m) != 3 || len(m[0]) != 0 {
+		t.Errorf("expected 3 empty rows, got %v (%v)", m, err)
+	}
+	in = "%%MatrixMarket matrix coordinate pattern general\n2 3 2\n1 3\n2 1\n"
+	m, _, err = ReadMatrixMarket(strings.NewReader(in))
+	want = [][]float64{{0.0, 0.0, 1.0}, {1.0, 0.0, 0.0}}
+	if err != nil || !Equal(m, want) {
+		t.Errorf("expected %v, got %v (%v)", want, m, err)
+	}
+	bad := []string{
+		"%%MatrixMarket matrix coordinate complex general\n1 1 1\n1 1 1 0\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 2\n1 1 1\n",
+		"%%MatrixMarket matrix coordinate real general\n2 2 1\n3 1 1\n",
+		"%%MatrixMarket matrix array real general\n1 1\n1\n2\n",
+		"%%MatrixMarket matrix coordinate real symmetric\n2 2 1\n1 2 1\n",
+		"%%MatrixMarket matrix coordinate real general\n1000000000000000 1 0\n",
+		"%%MatrixMarket matrix array real general\n4294967296 4294967296\n",
+		"%%MatrixMarket matrix coordinate real general\n2000000000 0 0\n",
+		"%%MatrixMarket matrix array real general\n1073741824 0\n",
+		"%%MatrixMarket matrix array real general\n32768 32768\n1\n",
+		"1 1\n1\n",
+	}
+	for _, in := range bad {
+		if _, _, err = ReadMatrixMarket(strings.NewReader(in)); !errors.Is(err, ErrFormat) {
+			t.Errorf("expected ErrFormat for %q, got %v", in, err)
+		}
+	}
+	_, _, err = ReadMatrixMarket(strings.NewReader(bad[6]))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+	_, _, err = ReadMatrixMarket(strings.NewReader(bad[0]))
+	if err == nil || !strings.Contains(err.Error(), "complex") {
+		t.Errorf("expected an error about complex matrices, got %v", err)
+	}
+}
+
+func TestWriteMatrixMarket(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{4.0, 1.0, 0.0}, {1.0, 3.0, 0.5}, {0.0, 0.5, 2.0}}
+	headers := []MatrixMarketHeader{
+		{},
+		{Format: "coordinate"},
+		{Symmetry: "symmetric", Comments: []string{" written by matf64"}},
+		{Format: "coordinate", Symmetry: "symmetric"},
+	}
+	for _, h := range headers {
+		var buf bytes.Buffer
+		if err := WriteMatrixMarket(&buf, m, h); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		n, _, err := ReadMatrixMarket(&buf)
+		if err != nil || !Equal(m, n) {
+			t.Errorf("round trip with %+v failed, got %v (%v)", h, n, err)
+		}
+	}
+	var buf bytes.Buffer
+	h := MatrixMarketHeader{Format: "coordinate", Field: "pattern", Symmetry: "symmetric"}
+	if err := WriteMatrixMarket(&buf, m, h); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	want := "%%MatrixMarket matrix coordinate pattern symmetric\n3 3 5\n1 1\n2 1\n2 2\n3 2\n3 3\n"
+	if s := buf.String(); s != want {
+		t.Errorf("expected %q, got %q", want, s)
+	}
+	if err := WriteMatrixMarket(&buf, m, MatrixMarketHeader{Symmetry: "skew-symmetric"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+	if err := WriteMatrixMarket(&buf, m, MatrixMarketHeader{Field: "integer"}); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument, got %v", err)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, math.NaN(), math.Inf(1)}, {math.Copysign(0.0, -1.0), 1e-310, 3.5}}
+	var buf bytes.Buffer
+	if err := Encode(&buf, m); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if buf.Len() != 24+8*6+4 {
+		t.Errorf("expected %d bytes, got %d", 24+8*6+4, buf.Len())
+	}
+	data := buf.Bytes()
+	n, err := Decode(bytes.NewReader(data))
+	if err != nil || !EqualApprox(m, n, Tol{NaNEqual: true}) || !math.Signbit(n[1][0]) {
+		t.Errorf("expected %v, got %v (%v)", m, n, err)
+	}
+	for _, k := range []int{0, 3, 24, 50, len(data) - 1} {
+		if _, err = Decode(bytes.NewReader(data[:k])); err != io.ErrUnexpectedEOF {
+			t.Errorf("expected io.ErrUnexpectedEOF when truncated to %d bytes, got %v", k, err)
+		}
+	}
+	for _, k := range []int{0, 4, 12, 40, len(data) - 2} {
+		bad := append([]byte(nil), data...)
+		bad[k] ^= 0x10
+		if _, err = Decode(bytes.NewReader(bad)); !errors.Is(err, ErrFormat) {
+			t.Errorf("expected ErrFormat when byte %d is corrupted, got %v", k, err)
+		}
+	}
+	if err = Encode(&buf, [][]float64{{1.0}, {}}); !errors.Is(err, ErrJagged) {
+		t.Errorf("expected ErrJagged, got %v", err)
+	}
+	empty := make([]byte, 28)
+	copy(empty, "MF64\x01")
+	binary.LittleEndian.PutUint64(empty[8:], 1<<22)
+	binary.LittleEndian.PutUint32(empty[24:], crc32.ChecksumIEEE(empty[:24]))
+	if _, err = Decode(bytes.NewReader(empty)); !errors.Is(err, ErrFormat) {
+		t.Errorf("expected ErrFormat for 4194304 empty rows, got %v", err)
+	}
+	if err = Encode(&buf, make([][]float64, 1<<17)); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("expected ErrInvalidArgument for 131072 empty rows, got %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Helper()
+	long := New(3, 5000)
+	Set(long, 0.25)
+	long[2][4999] = -1.0
+	for _, m := range [][][]float64{{}, {{}}, long} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, m); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		n, err := Decode(&buf)
+		if err != nil || !Equal(m, n) || len(n) != len(m) {
+			t.Errorf("round trip of a %dx? [][]float64 failed (%v)", len(m), err)
+		}
+	}
+}
+
+func TestSprint(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, -2.5}, {30.0, 4.25}}
+	if s := Sprint(m); s != "[[ 1 -2.5 ]\n [30  4.25]]" {
+		t.Errorf("unexpected output\n%s", s)
+	}
+	s := Sprint([][]float64{{1e-5, math.Inf(1)}, {math.NaN(), 2.0}})
+	if s != "[[1e-05  +Inf]\n [  NaN 2e+00]]" {
+		t.Errorf("unexpected output\n%s", s)
+	}
+	if s = Sprint(nil); s != "[]" {
+		t.Errorf("expected [], got %s", s)
+	}
+	big := New(40, 40)
+	for i := range big {
+		for j := range big[i] {
+			big[i][j] = float64(40*i + j)
+		}
+	}
+	lines := strings.Split(Sprint(big), "\n")
+	if len(lines) != 7 || lines[3] != " ..." || lines[0] != "[[   0    1    2 ...   37   38   39]" {
+		t.Errorf("unexpected elided output\n%s", strings.Join(lines, "\n"))
+	}
+	f := Format{Precision: 2, Notation: Fixed}
+	if s = f.Sprint([][]float64{{1.0, 1e-6}}); s != "[[1.00 0.00]]" {
+		t.Errorf("expected [[1.00 0.00]], got %s", s)
+	}
+	f.Notation = Scientific
+	if s = f.Sprint([][]float64{{1.0, -1250.0}}); s != "[[1.00e+00 -1.25e+03]]" {
+		t.Errorf("expected [[1.00e+00 -1.25e+03]], got %s", s)
+	}
+}
+
+func TestSprintStyles(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, -2.5}, {30.0, 4.25}}
+	md := Format{Precision: -1, Style: Markdown}.Sprint(m)
+	want := "|  0 |     1 |\n|---:|------:|\n|  1 | -2.5  |\n| 30 |  4.25 |\n"
+	if md != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, md)
+	}
+	for _, e := range [][][]float64{{}, {{}}} {
+		if s := (Format{Style: Markdown}).Sprint(e); s != Sprint(e) {
+			t.Errorf("expected %q for an empty table, got %q", Sprint(e), s)
+		}
+	}
+	tex := Format{Precision: 1, Style: LaTeX, Notation: Scientific}.Sprint([][]float64{{1.5e-5, math.Inf(-1)}})
+	want = "\\begin{bmatrix}\n1.5 \\times 10^{-5} & -\\infty\n\\end{bmatrix}"
+	if tex != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, tex)
+	}
+	f := Format{Precision: -1, Style: LaTeX, Threshold: 10, EdgeItems: 1}
+	tex = f.Sprint(New(5, 5))
+	want = "\\begin{bmatrix}\n0 & \\cdots & 0 \\\\\n\\vdots & \\ddots & \\vdots \\\\\n0 & \\cdots & 0\n\\end{bmatrix}"
+	if tex != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, tex)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Helper()
+	want := [][]float64{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}}
+	inputs := []string{
+		"[1 2 3; 4 5 6]",
+		"[1, 2, 3; 4, 5, 6];",
+		"[[1, 2, 3], [4, 5, 6]]",
+		"[[1 2 3]\n [4 5 6]]",
+		"[[1, 2,\n  3],\n [4, 5, 6],\n]",
+		"\n\t1 2 3\n\t4 5 6\n",
+		"[\n1 2 3\n4 5 6\n]",
+		"1e0 +2 0.3e1; 4 5. 60e-1",
+	}
+	for _, in := range inputs {
+		m, err := Parse(in)
+		if err != nil || !Equal(m, want) {
+			t.Errorf("expected %v for %q, got %v (%v)", want, in, m, err)
+		}
+	}
+	m, err := Parse("[inf -Inf NaN]")
+	if err != nil || len(m) != 1 || !math.IsInf(m[0][0], 1) || !math.IsInf(m[0][1], -1) || !math.IsNaN(m[0][2]) {
+		t.Errorf("expected [[+Inf -Inf NaN]], got %v (%v)", m, err)
+	}
+	for in, n := range map[string]int{"[]": 0, "": 0, "[[]]": 1} {
+		if m, err = Parse(in); err != nil || m == nil || len(m) != n {
+			t.Errorf("expected %d empty rows for %q, got %v (%v)", n, in, m, err)
+		}
+	}
+	bad := map[string][2]int{
+		"[1 2; 3]":           {1, 7},
+		"[1 2\n 3 x]":        {2, 4},
+		"[1 2; 3 4":          {1, 10},
+		"[[1, 2], [3, 4]] 5": {1, 18},
+		"[1,, 2]":            {1, 4},
+		"[[1 2] 3]":          {1, 8},
+	}
+	for in, pos := range bad {
+		_, err = Parse(in)
+		var se *SyntaxError
+		if !errors.As(err, &se) || se.Line != pos[0] || se.Col != pos[1] {
+			t.Errorf("expected an error at %v for %q, got %v", pos, in, err)
+		}
+		if !errors.Is(err, ErrFormat) {
+			t.Errorf("expected the error for %q to wrap ErrFormat", in)
+		}
+	}
+}
+
+func TestParseSprint(t *testing.T) {
+	t.Helper()
+	ms := [][][]float64{
+		{{1.0, -2.5}, {30.0, 4.25}},
+		{{0.1, 1e-300, math.Inf(-1)}, {math.NaN(), -7e20, 1.0 / 3.0}},
+		{{}},
+		RandMat(4, 4),
+	}
+	for _, m := range ms {
+		n, err := Parse(Sprint(m))
+		if err != nil || !EqualApprox(m, n, Tol{NaNEqual: true}) {
+			t.Errorf("round trip of\n%s\nfailed, got %v (%v)", Sprint(m), n, err)
+		}
+	}
+}
+
+func TestRand(t *testing.T) {
+	t.Helper()
+	r, s := NewRand(7), NewRand(7)
+	m, n := r.RandMat(3, 5, -2.0, 2.0), s.RandMat(3, 5, -2.0, 2.0)
+	if len(m) != 3 || len(m[0]) != 5 || !Equal(m, n) {
+		t.Errorf("expected identical 3x5 [][]float64s for identical seeds, got %v and %v", m, n)
+	}
+	for i := range m {
+		for j := range m[i] {
+			if m[i][j] < -2.0 || m[i][j] >= 2.0 {
+				t.Errorf("at (%d, %d), expected [-2.0, 2.0), got %f", i, j, m[i][j])
+			}
+		}
+	}
+	v, u := r.RandVec(4, 10.0), s.RandVec(4, 10.0)
+	for i := range v {
+		if v[i] != u[i] || v[i] < 0.0 || v[i] >= 10.0 {
+			t.Errorf("at index %d, expected identical values in [0, 10.0), got %f and %f", i, v[i], u[i])
+		}
+	}
+	if w := NewRand(8).RandVec(4, 10.0); w[0] == NewRand(7).RandVec(4, 10.0)[0] {
+		t.Errorf("expected different seeds to give different values")
+	}
+	// Concurrent calls on the same Rand each receive consecutive values, so
+	// the values drawn are those of the sequence, in some order.
+	r, s = NewRand(1), NewRand(1)
+	want := s.RandVec(400)
+	got := make([][]float64, 4)
+	var wg sync.WaitGroup
+	for k := range got {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			got[k] = r.RandVec(100)
+		}(k)
+	}
+	wg.Wait()
+	seen := make(map[float64]bool)
+	for _, x := range want {
+		seen[x] = true
+	}
+	for k := range got {
+		for _, x := range got[k] {
+			if !seen[x] {
+				t.Errorf("unexpected value %v", x)
+			}
+		}
+	}
+}
+
+func TestRandDistributions(t *testing.T) {
+	t.Helper()
+	r := NewRand(11)
+	n := 200
+	tests := []struct {
+		name      string
+		m         [][]float64
+		mean, std float64
+	}{
+		{"RandNormal", r.RandNormal(n, n, 2.0, 3.0), 2.0, 3.0},
+		{"RandLogNormal", r.RandLogNormal(n, n, 0.0, 0.5), math.Exp(0.125), math.Sqrt((math.Exp(0.25) - 1.0) * math.Exp(0.25))},
+		{"RandExp", r.RandExp(n, n, 4.0), 0.25, 0.25},
+		{"RandPoisson", r.RandPoisson(n, n, 3.5), 3.5, math.Sqrt(3.5)},
+		{"RandPoisson", r.RandPoisson(n, n, 250.0), 250.0, math.Sqrt(250.0)},
+		{"RandBernoulli", r.RandBernoulli(n, n, 0.3), 0.3, math.Sqrt(0.21)},
+		{"RandTruncNormal", r.RandTruncNormal(n, n, 1.0, 2.0, 1.0, math.Inf(1)), 1.0 + 2.0*math.Sqrt(2.0/math.Pi), 2.0 * math.Sqrt(1.0-2.0/math.Pi)},
+	}
+	for _, test := range tests {
+		if len(test.m) != n || len(test.m[0]) != n {
+			t.Errorf("%s: expected a %dx%d [][]float64", test.name, n, n)
+		}
+		// The mean of n*n samples has a standard error of std/n.
+		if mean := Avg(test.m); math.Abs(mean-test.mean) > 5.0*test.std/float64(n) {
+			t.Errorf("%s: expected a mean of %v, got %v", test.name, test.mean, mean)
+		}
+		if std := Std(test.m); math.Abs(std-test.std) > 0.05*test.std {
+			t.Errorf("%s: expected a standard deviation of %v, got %v", test.name, test.std, std)
+		}
+	}
+	for _, lam := range []float64{3.5, 250.0} {
+		if !All(r.RandPoisson(10, 10, lam), func(x *float64) bool { return *x >= 0.0 && *x == math.Trunc(*x) }) {
+			t.Errorf("expected whole, non-negative Poisson entries for lambda %v", lam)
+		}
+	}
+	if !Equal(NewRand(3).RandNormal(2, 3, 0.0, 1.0), NewRand(3).RandNormal(2, 3, 0.0, 1.0)) {
+		t.Errorf("expected identical values for identical seeds")
+	}
+}
+
+func TestRandTruncNormal(t *testing.T) {
+	t.Helper()
+	r := NewRand(5)
+	bounds := [][2]float64{{8.0, 8.5}, {8.0, math.Inf(1)}, {-0.1, 0.1}, {-1.0, 2.0}, {math.Inf(-1), -6.0}, {-20.0, -19.99}}
+	for _, b := range bounds {
+		m := r.RandTruncNormal(50, 50, 0.0, 1.0, b[0], b[1])
+		if !All(m, func(x *float64) bool { return *x >= b[0] && *x <= b[1] }) {
+			t.Errorf("expected all entries in [%v, %v]", b[0], b[1])
+		}
+	}
+	// Far in the tail, the distribution is nearly exponential with rate a,
+	// starting at a.
+	m := r.RandTruncNormal(100, 100, 0.0, 1.0, 8.0, math.Inf(1))
+	if mean := Avg(m); math.Abs(mean-8.0-1.0/8.12) > 0.01 {
+		t.Errorf("expected a mean near %v, got %v", 8.0+1.0/8.12, mean)
+	}
+}
+
+func TestRandMultiNormal(t *testing.T) {
+	t.Helper()
+	r := NewRand(9)
+	mean := []float64{1.0, -2.0, 0.5}
+	cov := [][]float64{{4.0, 1.2, -0.6}, {1.2, 1.0, 0.0}, {-0.6, 0.0, 0.25}}
+	m, err := r.RandMultiNormal(20000, mean, cov)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if len(m) != 20000 || len(m[0]) != 3 {
+		t.Fatalf("expected a 20000x3 [][]float64")
+	}
+	for j := range mean {
+		if avg := Avg(m, 1, j); math.Abs(avg-mean[j]) > 0.05 {
+			t.Errorf("expected a mean of %v for column %d, got %v", mean[j], j, avg)
+		}
+	}
+	if got := Cov(m); !EqualApprox(got, cov, Tol{Abs: 0.1}) {
+		t.Errorf("expected a covariance of %v, got %v", cov, got)
+	}
+	_, err = r.RandMultiNormal(10, []float64{0.0, 0.0}, [][]float64{{1.0, 2.0}, {2.0, 1.0}})
+	if !errors.Is(err, ErrNotPositiveDefinite) {
+		t.Errorf("expected ErrNotPositiveDefinite, got %v", err)
+	}
+}
+
+func TestRandOrthogonal(t *testing.T) {
+	t.Helper()
+	r := NewRand(21)
+	q := r.RandOrthogonal(6)
+	if !EqualApprox(Dot(q, T(q)), I(6), Tol{Abs: 1e-12}) || !EqualApprox(Dot(T(q), q), I(6), Tol{Abs: 1e-12}) {
+		t.Errorf("expected an orthogonal [][]float64, got %v", q)
+	}
+	if !Equal(q, NewRand(21).RandOrthogonal(6)) {
+		t.Errorf("expected identical values for identical seeds")
+	}
+	// Without the sign fix, the first entry of the first column would always
+	// have the sign of the first entry of the normal [][]float64, while the
+	// Haar measure makes it symmetric around zero.
+	pos := 0
+	for k := 0; k < 400; k++ {
+		if r.RandOrthogonal(2)[0][0] > 0.0 {
+			pos++
+		}
+	}
+	if pos < 150 || pos > 250 {
+		t.Errorf("expected about half of the entries to be positive, got %d of 400", pos)
+	}
+	if q = RandOrthogonal(0); len(q) != 0 {
+		t.Errorf("expected an empty [][]float64, got %v", q)
+	}
+}
+
+func TestRandSPD(t *testing.T) {
+	t.Helper()
+	r := NewRand(4)
+	for _, cond := range []float64{1.0, 10.0, 1e6} {
+		m := r.RandSPD(8, cond)
+		if !Equal(m, T(m)) {
+			t.Errorf("expected a symmetric [][]float64")
+		}
+		if _, err := Cholesky(m); err != nil {
+			t.Errorf("expected a positive definite [][]float64, got %v", err)
+		}
+		if c, err := Cond(m); err != nil || math.Abs(c-cond) > 1e-6*cond {
+			t.Errorf("expected a condition number of %v, got %v (%v)", cond, c, err)
+		}
+	}
+}
+
+func TestRandDiagDominant(t *testing.T) {
+	t.Helper()
+	m := NewRand(2).RandDiagDominant(10)
+	for i := range m {
+		sum := 0.0
+		for j := range m[i] {
+			if j != i {
+				sum += math.Abs(m[i][j])
+			}
+		}
+		if m[i][i] <= sum {
+			t.Errorf("row %d is not diagonally dominant: %v", i, m[i])
+		}
+	}
+}
+
+func TestRandLowRank(t *testing.T) {
+	t.Helper()
+	r := NewRand(3)
+	for _, k := range []int{0, 1, 3, 5} {
+		m := r.RandLowRank(7, 5, k)
+		if len(m) != 7 || len(m[0]) != 5 {
+			t.Errorf("expected a 7x5 [][]float64")
+		}
+		if rank, err := Rank(m); err != nil || rank != k {
+			t.Errorf("expected rank %d, got %d (%v)", k, rank, err)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic for a rank larger than the [][]float64")
+		}
+	}()
+	r.RandLowRank(7, 5, 6)
+}
+
+func TestRandBanded(t *testing.T) {
+	t.Helper()
+	m := NewRand(6).RandBanded(6, 8, 1, 2, 1.0, 2.0)
+	if len(m) != 6 || len(m[0]) != 8 {
+		t.Fatalf("expected a 6x8 [][]float64")
+	}
+	for i := range m {
+		for j := range m[i] {
+			inBand := j-i >= -1 && j-i <= 2
+			if inBand && (m[i][j] < 1.0 || m[i][j] >= 2.0) {
+				t.Errorf("at (%d, %d), expected [1.0, 2.0), got %f", i, j, m[i][j])
+			}
+			if !inBand && m[i][j] != 0.0 {
+				t.Errorf("at (%d, %d), expected 0.0 outside the band, got %f", i, j, m[i][j])
+			}
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}, {7.0, 8.0, 9.0}}
+	if s := Slice(m, 1, 3, 0, 2); !Equal(s, [][]float64{{4.0, 5.0}, {7.0, 8.0}}) {
+		t.Errorf("expected [[4 5] [7 8]], got %v", s)
+	}
+	if s := Slice(m, -2, 3, 1, -1); !Equal(s, [][]float64{{5.0}, {8.0}}) {
+		t.Errorf("expected [[5] [8]], got %v", s)
+	}
+	if s := Slice(m, 1, 1, 0, 3); len(s) != 0 {
+		t.Errorf("expected no rows, got %v", s)
+	}
+	s := Slice(m, 0, 2, 0, 2)
+	MultScalar(s, 10.0)
+	want := [][]float64{{10.0, 20.0, 3.0}, {40.0, 50.0, 6.0}, {7.0, 8.0, 9.0}}
+	if !Equal(m, want) {
+		t.Errorf("expected the parent to be %v, got %v", want, m)
+	}
+	s[0] = append(s[0], -1.0)
+	if m[0][2] != 3.0 {
+		t.Errorf("expected appending to a row of the slice to leave the parent intact, got %v", m[0])
+	}
+	for _, b := range [][4]int{{0, 4, 0, 1}, {2, 1, 0, 1}, {0, 1, -4, 1}, {0, 1, 2, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic for the bounds %v", b)
+				}
+			}()
+			Slice(m, b[0], b[1], b[2], b[3])
+		}()
+	}
+}
+
+func TestSliceCopy(t *testing.T) {
+	t.Helper()
+	m := [][]float64{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}}
+	s := SliceCopy(m, 0, -1, 1, 3)
+	if !Equal(s, [][]float64{{2.0, 3.0}}) {
+		t.Errorf("expected [[2 3]], got %v", s)
+	}
+	s[0][0] = 100.0
+	if m[0][1] != 2.0 {
+		t.Errorf("expected the parent to be unchanged, got %v", m)
+	}
+}
+
+func TestRandStructuredConcurrent(t *testing.T) {
+	t.Helper()
+	// Each call draws all its values at once, so that concurrent calls give
+	// the same [][]float64s as sequential ones, in some order.
+	gens := map[string]func(r *Rand) [][]float64{
+		"RandDiagDominant": func(r *Rand) [][]float64 { return r.RandDiagDominant(5) },
+		"RandLowRank":      func(r *Rand) [][]float64 { return r.RandLowRank(5, 4, 2) },
+		"RandOrthogonal":   func(r *Rand) [][]float64 { return r.RandOrthogonal(4) },
+	}
+	for name, gen := range gens {
+		seq := NewRand(13)
+		want := make([][][]float64, 8)
+		for k := range want {
+			want[k] = gen(seq)
+		}
+		r := NewRand(13)
+		got := make([][][]float64, len(want))
+		var wg sync.WaitGroup
+		for k := range got {
+			wg.Add(1)
+			go func(k int) {
+				defer wg.Done()
+				got[k] = gen(r)
+			}(k)
+		}
+		wg.Wait()
+		for k := range got {
+			found := false
+			for i := range want {
+				found = found || Equal(got[k], want[i])
+			}
+			if !found {
+				t.Errorf("%s: concurrent call %d does not match any sequential call", name, k)
+			}
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package matf64
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+MatrixMarketHeader describes how a [][]float64 is stored in the Matrix Market
+exchange format. Format is "array" for dense storage of every entry in column
+major order, or "coordinate" for sparse storage of the non-zero entries. Field
+is "real", "integer" or "pattern", where a pattern only records the positions
+of the non-zero entries, which read as 1.0. Symmetry is "general",
+"symmetric" or "skew-symmetric", in which case only the lower triangle is
+stored. Comments holds the comment lines following the banner, without their
+leading %.
+
+When writing, empty fields default to "array", "real" and "general".
+*/
+type MatrixMarketHeader struct {
+	Format   string
+	Field    string
+	Symmetry string
+	Comments []string
+}
+
+/*
+matrixMarketMaxEntries bounds the number of entries of the dense [][]float64
+built by ReadMatrixMarket, so that a corrupted size line is reported instead of
+overflowing.
+*/
+const matrixMarketMaxEntries = 1 << 26
+
+/*
+ReadMatrixMarket reads a [][]float64 from the Matrix Market exchange format,
+such as the .mtx files of the Matrix Market and SuiteSparse collections. For
+example:
+
+	m, header, err := matf64.ReadMatrixMarket(f)
+
+Coordinate files are expanded into a dense [][]float64, and symmetric and
+skew-symmetric files into the full matrix. A FormatError naming the offending
+line is returned for malformed input, for sizes of more than 2^26 entries or
+65536 empty rows, and for complex and hermitian matrices, which can not be held
+in a [][]float64.
+*/
+func ReadMatrixMarket(r io.Reader) ([][]float64, MatrixMarketHeader, error) {
+	const op = "ReadMatrixMarket()"
+	var h MatrixMarketHeader
+	sc := bufio.NewScanner(r)
+	line := 0
+	fail := func(format string, a ...interface{}) ([][]float64, MatrixMarketHeader, error) {
+		s := fmt.Sprintf("line %d: ", line) + fmt.Sprintf(format, a...)
+		return nil, h, &FormatError{Op: op, Msg: s}
+	}
+	// next returns the fields of the next line which is neither blank nor a
+	// comment, or nil at the end of the input.
+	next := func() []string {
+		for sc.Scan() {
+			line++
+			text := strings.TrimSpace(sc.Text())
+			if strings.HasPrefix(text, "%") {
+				h.Comments = append(h.Comments, strings.TrimPrefix(text, "%"))
+				continue
+			}
+			if text != "" {
+				return strings.Fields(text)
+			}
+		}
+		return nil
+	}
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, h, err
+		}
+		return nil, h, io.ErrUnexpectedEOF
+	}
+	line++
+	banner := strings.Fields(strings.ToLower(sc.Text()))
+	if len(banner) != 5 || banner[0] != "%%matrixmarket" || banner[1] != "matrix" {
+		return fail("expected a %%%%MatrixMarket matrix banner")
+	}
+	h.Format, h.Field, h.Symmetry = banner[2], banner[3], banner[4]
+	if h.Format != "array" && h.Format != "coordinate" {
+		return fail("unknown format %q", h.Format)
+	}
+	switch h.Field {
+	case "real", "double", "integer", "pattern":
+	case "complex":
+		return fail("complex matrices are not supported")
+	default:
+		return fail("unknown field %q", h.Field)
+	}
+	switch h.Symmetry {
+	case "general", "symmetric", "skew-symmetric":
+	case "hermitian":
+		return fail("hermitian matrices are not supported")
+	default:
+		return fail("unknown symmetry %q", h.Symmetry)
+	}
+	if h.Format == "array" && h.Field == "pattern" {
+		return fail("pattern matrices must use the coordinate format")
+	}
+
+	size := next()
+	if err := sc.Err(); err != nil {
+		return nil, h, err
+	}
+	want := 3
+	if h.Format == "array" {
+		want = 2
+	}
+	if len(size) != want {
+		return fail("expected %d integers for the size, but found %d fields", want, len(size))
+	}
+	dims := make([]int, want)
+	for i := range size {
+		d, err := strconv.Atoi(size[i])
+		if err != nil || d < 0 {
+			return fail("invalid size %q", size[i])
+		}
+		dims[i] = d
+	}
+	rows, cols := dims[0], dims[1]
+	if rows > 0 && cols > matrixMarketMaxEntries/rows {
+		return fail("size %dx%d is too large, at most %d entries can be read", rows, cols, matrixMarketMaxEntries)
+	}
+	// Rows without columns are not backed by any entries, so their number is
+	// bounded as in Decode.
+	if cols == 0 && rows > encodingMaxEmpty {
+		return fail("size %dx%d is too large, at most %d empty rows can be read", rows, cols, encodingMaxEmpty)
+	}
+	if h.Symmetry != "general" && rows != cols {
+		return fail("%s matrices must be square, but the size is %dx%d", h.Symmetry, rows, cols)
+	}
+	// The entries are collected as they are read, and the [][]float64 is only
+	// allocated once all of them are present.
+	type entry struct {
+		i, j int
+		x    float64
+	}
+	var entries []entry
+	if h.Format == "array" {
+		for j := 0; j < cols; j++ {
+			start := 0
+			switch h.Symmetry {
+			case "symmetric":
+				start = j
+			case "skew-symmetric":
+				start = j + 1
+			}
+			for i := start; i < rows; i++ {
+				f := next()
+				if f == nil {
+					if err := sc.Err(); err != nil {
+						return nil, h, err
+					}
+					return fail("expected more entries")
+				}
+				if len(f) != 1 {
+					return fail("expected 1 value, but found %d fields", len(f))
+				}
+				x, err := strconv.ParseFloat(f[0], 64)
+				if err != nil {
+					return fail("invalid value %q", f[0])
+				}
+				entries = append(entries, entry{i, j, x})
+			}
+		}
+	} else {
+		nnz := dims[2]
+		fields := 3
+		if h.Field == "pattern" {
+			fields = 2
+		}
+		for k := 0; k < nnz; k++ {
+			f := next()
+			if f == nil {
+				if err := sc.Err(); err != nil {
+					return nil, h, err
+				}
+				return fail("expected %d entries, but found %d", nnz, k)
+			}
+			if len(f) != fields {
+				return fail("expected %d fields, but found %d", fields, len(f))
+			}
+			i, err := strconv.Atoi(f[0])
+			if err != nil || i < 1 || i > rows {
+				return fail("invalid row index %q", f[0])
+			}
+			j, err := strconv.Atoi(f[1])
+			if err != nil || j < 1 || j > cols {
+				return fail("invalid column index %q", f[1])
+			}
+			i--
+			j--
+			x := 1.0
+			if fields == 3 {
+				if x, err = strconv.ParseFloat(f[2], 64); err != nil {
+					return fail("invalid value %q", f[2])
+				}
+			}
+			if h.Symmetry != "general" && j > i {
+				return fail("entry (%d, %d) is above the diagonal of a %s matrix", i+1, j+1, h.Symmetry)
+			}
+			if h.Symmetry == "skew-symmetric" && i == j {
+				return fail("entry (%d, %d) is on the diagonal of a skew-symmetric matrix", i+1, j+1)
+			}
+			entries = append(entries, entry{i, j, x})
+		}
+	}
+	if f := next(); f != nil {
+		return fail("unexpected data after the last entry")
+	}
+	if err := sc.Err(); err != nil {
+		return nil, h, err
+	}
+	sign := 1.0
+	if h.Symmetry == "skew-symmetric" {
+		sign = -1.0
+	}
+	m := New(rows, cols)
+	for _, e := range entries {
+		m[e.i][e.j] += e.x
+		if e.i != e.j && h.Symmetry != "general" {
+			m[e.j][e.i] += sign * e.x
+		}
+	}
+	if h.Field == "double" {
+		h.Field = "real"
+	}
+	return m, h, nil
+}
+
+/*
+WriteMatrixMarket writes a [][]float64 in the Matrix Market exchange format
+described by the header. For example, the non-zero entries of the lower
+triangle of a symmetric [][]float64 are written with:
+
+	h := matf64.MatrixMarketHeader{Format: "coordinate", Symmetry: "symmetric"}
+	err := matf64.WriteMatrixMarket(f, m, h)
+
+An ArgError is returned if the header is not valid, or does not describe m,
+such as a "symmetric" header for a [][]float64 which is not exactly
+symmetric, or an "integer" header for a [][]float64 with fractional entries.
+Real values are written in the shortest form which reads back exactly.
+*/
+func WriteMatrixMarket(w io.Writer, m [][]float64, h MatrixMarketHeader) error {
+	const op = "WriteMatrixMarket()"
+	rows, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	if h.Format == "" {
+		h.Format = "array"
+	}
+	if h.Field == "" {
+		h.Field = "real"
+	}
+	if h.Symmetry == "" {
+		h.Symmetry = "general"
+	}
+	invalid := func(format string, a ...interface{}) error {
+		return &ArgError{Op: op, Msg: fmt.Sprintf(format, a...)}
+	}
+	switch {
+	case h.Format != "array" && h.Format != "coordinate":
+		return invalid("unknown format %q", h.Format)
+	case h.Field != "real" && h.Field != "integer" && h.Field != "pattern":
+		return invalid("unknown field %q", h.Field)
+	case h.Symmetry != "general" && h.Symmetry != "symmetric" && h.Symmetry != "skew-symmetric":
+		return invalid("unknown symmetry %q", h.Symmetry)
+	case h.Format == "array" && h.Field == "pattern":
+		return invalid("pattern matrices must use the coordinate format")
+	case h.Symmetry != "general" && rows != cols:
+		return invalid("%s matrices must be square, but received %dx%d", h.Symmetry, rows, cols)
+	}
+	sign := 1.0
+	if h.Symmetry == "skew-symmetric" {
+		sign = -1.0
+	}
+	// stored reports whether the entry in row i and column j is written.
+	stored := func(i, j int) bool {
+		switch h.Symmetry {
+		case "symmetric":
+			return i >= j
+		case "skew-symmetric":
+			return i > j
+		}
+		return true
+	}
+	nnz := 0
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			x := m[i][j]
+			if h.Symmetry != "general" && m[j][i] != sign*x {
+				return invalid("entries (%d, %d) and (%d, %d) are not %s", i, j, j, i, h.Symmetry)
+			}
+			if h.Field == "integer" && x != math.Trunc(x) {
+				return invalid("entry (%d, %d) is not an integer: %v", i, j, x)
+			}
+			if stored(i, j) && x != 0.0 {
+				nnz++
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix %s %s %s\n", h.Format, h.Field, h.Symmetry)
+	for _, c := range h.Comments {
+		fmt.Fprintf(bw, "%%%s\n", c)
+	}
+	format := func(x float64) string {
+		if h.Field == "integer" {
+			return strconv.FormatFloat(x, 'f', -1, 64)
+		}
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	if h.Format == "array" {
+		fmt.Fprintf(bw, "%d %d\n", rows, cols)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				if stored(i, j) {
+					fmt.Fprintln(bw, format(m[i][j]))
+				}
+			}
+		}
+	} else {
+		fmt.Fprintf(bw, "%d %d %d\n", rows, cols, nnz)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				if !stored(i, j) || m[i][j] == 0.0 {
+					continue
+				}
+				if h.Field == "pattern" {
+					fmt.Fprintf(bw, "%d %d\n", i+1, j+1)
+				} else {
+					fmt.Fprintf(bw, "%d %d %s\n", i+1, j+1, format(m[i][j]))
+				}
+			}
+		}
+	}
+	return bw.Flush()
+}
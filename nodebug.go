@@ -0,0 +1,6 @@
+//go:build !matf64debug
+
+package matf64
+
+// debug enables the argument checks of the checked mode, see the package documentation.
+const debug = false
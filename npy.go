@@ -0,0 +1,390 @@
+package matf64
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+ErrFormat is wrapped by the FormatError returned by the functions reading
+[][]float64s and []float64s from files, when their input is not valid.
+*/
+var ErrFormat = errors.New("matf64: malformed input")
+
+/*
+FormatError describes input which could not be read by the function named in
+Op, such as a .npy file with an unsupported data type. FormatError wraps
+ErrFormat.
+*/
+type FormatError struct {
+	Op  string
+	Msg string
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("matf64.%s: %s", e.Op, e.Msg)
+}
+
+// Unwrap returns ErrFormat.
+func (e *FormatError) Unwrap() error { return ErrFormat }
+
+/*
+NPYOptions configures the layout of the data written by WriteNPY and WriteNPZ.
+The zero value writes little endian data in row major (C) order, which is the
+default of NumPy. The data is always float64, and NumPy reads all the layouts
+transparently.
+*/
+type NPYOptions struct {
+	// FortranOrder writes the data in column major order.
+	FortranOrder bool
+	// BigEndian writes the data in big endian byte order.
+	BigEndian bool
+}
+
+const npyMagic = "\x93NUMPY"
+
+/*
+npyMaxEntries bounds the number of entries of the arrays read by ReadNPY and
+ReadNPZ, so that a corrupted shape is reported instead of overflowing.
+*/
+const npyMaxEntries = 1 << 30
+
+var (
+	npyDescr   = regexp.MustCompile(`'descr'\s*:\s*'([^']*)'`)
+	npyFortran = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	npyShape   = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+/*
+ReadNPY reads an array from NumPy's .npy format, as written by numpy.save. The
+result is a []float64 for a one dimensional array, and a [][]float64 for a two
+dimensional one. For example:
+
+	a, err := matf64.ReadNPY(f)
+	if err != nil {
+		...
+	}
+	m, ok := a.([][]float64)
+
+The array must have a float64 data type, in either byte order, and may be
+stored in either C or Fortran order. A FormatError is returned for any other
+array, or one with more than 2^30 entries or 65536 empty rows, and
+io.ErrUnexpectedEOF if the data is truncated.
+*/
+func ReadNPY(r io.Reader) (interface{}, error) {
+	return readNPY("ReadNPY()", r)
+}
+
+/*
+WriteNPY writes a []float64 or a [][]float64 in NumPy's .npy format, so that
+it can be read with numpy.load. For example:
+
+	err := matf64.WriteNPY(f, m, matf64.NPYOptions{})
+
+A [][]float64 is written as a two dimensional array, and must not be jagged.
+*/
+func WriteNPY(w io.Writer, m interface{}, opts NPYOptions) error {
+	return writeNPY("WriteNPY()", w, m, opts)
+}
+
+/*
+ReadNPZ reads all the arrays of a NumPy .npz archive, as written by numpy.savez
+or numpy.savez_compressed, from r, which holds size bytes. For example:
+
+	f, err := os.Open("data.npz")
+	...
+	fi, err := f.Stat()
+	...
+	arrays, err := matf64.ReadNPZ(f, fi.Size())
+
+The arrays are keyed by their names in the archive, without the .npy suffix,
+and are each a []float64 or a [][]float64 as described in ReadNPY.
+*/
+func ReadNPZ(r io.ReaderAt, size int64) (map[string]interface{}, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	arrays := make(map[string]interface{}, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		a, err := readNPY("ReadNPZ()", rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		arrays[strings.TrimSuffix(f.Name, ".npy")] = a
+	}
+	return arrays, nil
+}
+
+/*
+WriteNPZ writes named arrays into a NumPy .npz archive, which can be read with
+numpy.load. Each array must be a []float64 or a [][]float64, and is written as
+described in WriteNPY. For example:
+
+	arrays := map[string]interface{}{"weights": m, "bias": v}
+	err := matf64.WriteNPZ(f, arrays, matf64.NPYOptions{})
+
+The arrays are stored uncompressed and sorted by name, as numpy.savez does.
+*/
+func WriteNPZ(w io.Writer, arrays map[string]interface{}, opts NPYOptions) error {
+	names := make([]string, 0, len(arrays))
+	for name := range arrays {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	zw := zip.NewWriter(w)
+	for _, name := range names {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name + ".npy", Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if err := writeNPY("WriteNPZ()", f, arrays[name], opts); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+/*
+readNPY reads a single .npy array from r, naming op in its errors.
+*/
+func readNPY(op string, r io.Reader) (interface{}, error) {
+	pre := make([]byte, len(npyMagic)+2)
+	if _, err := io.ReadFull(r, pre); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	if string(pre[:len(npyMagic)]) != npyMagic {
+		return nil, &FormatError{Op: op, Msg: "not a .npy file"}
+	}
+	var n int
+	switch major := pre[len(npyMagic)]; major {
+	case 1:
+		var b [2]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		n = int(binary.LittleEndian.Uint16(b[:]))
+	case 2, 3:
+		var b [4]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		n = int(binary.LittleEndian.Uint32(b[:]))
+		if n < 0 || n > 1<<20 {
+			return nil, &FormatError{Op: op, Msg: fmt.Sprintf("header length %d is too large", n)}
+		}
+	default:
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf("unsupported .npy version %d", major)}
+	}
+	header := make([]byte, n)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	var order binary.ByteOrder
+	descr := npyDescr.FindSubmatch(header)
+	if descr == nil {
+		return nil, &FormatError{Op: op, Msg: "missing descr in header"}
+	}
+	switch string(descr[1]) {
+	case "<f8", "=f8":
+		order = binary.LittleEndian
+	case ">f8":
+		order = binary.BigEndian
+	default:
+		s := "unsupported data type %q, only float64 arrays can be read"
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf(s, descr[1])}
+	}
+	fortran := npyFortran.FindSubmatch(header)
+	if fortran == nil {
+		return nil, &FormatError{Op: op, Msg: "missing fortran_order in header"}
+	}
+	match := npyShape.FindSubmatch(header)
+	if match == nil {
+		return nil, &FormatError{Op: op, Msg: "missing shape in header"}
+	}
+	var dims []int
+	for _, d := range strings.Split(string(match[1]), ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		x, err := strconv.Atoi(d)
+		if err != nil || x < 0 {
+			return nil, &FormatError{Op: op, Msg: fmt.Sprintf("invalid shape (%s)", match[1])}
+		}
+		dims = append(dims, x)
+	}
+	size := 1
+	for _, d := range dims {
+		if d > 0 && size > npyMaxEntries/d {
+			s := "shape (%s) is too large, at most %d entries can be read"
+			return nil, &FormatError{Op: op, Msg: fmt.Sprintf(s, match[1], npyMaxEntries)}
+		}
+		size *= d
+	}
+	// Rows without columns are not backed by any data, so their number is
+	// bounded as in Decode.
+	if len(dims) == 2 && dims[1] == 0 && dims[0] > encodingMaxEmpty {
+		s := "shape (%s) is too large, at most %d empty rows can be read"
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf(s, match[1], encodingMaxEmpty)}
+	}
+	switch len(dims) {
+	case 1:
+		return readFloats(r, order, dims[0])
+	case 2:
+		rows, cols := dims[0], dims[1]
+		if string(fortran[1]) == "False" {
+			var m [][]float64
+			for i := 0; i < rows; i++ {
+				row, err := readFloats(r, order, cols)
+				if err != nil {
+					return nil, err
+				}
+				m = append(m, row)
+			}
+			if m == nil {
+				m = [][]float64{}
+			}
+			return m, nil
+		}
+		v, err := readFloats(r, order, rows*cols)
+		if err != nil {
+			return nil, err
+		}
+		m := New(rows, cols)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				m[i][j] = v[j*rows+i]
+			}
+		}
+		return m, nil
+	default:
+		s := "only 1 and 2 dimensional arrays can be read, but the shape is (%s)"
+		return nil, &FormatError{Op: op, Msg: fmt.Sprintf(s, match[1])}
+	}
+}
+
+/*
+writeNPY writes a single .npy array to w, naming op in its errors.
+*/
+func writeNPY(op string, w io.Writer, m interface{}, opts NPYOptions) error {
+	var shape string
+	var data [][]float64
+	switch v := m.(type) {
+	case []float64:
+		shape = fmt.Sprintf("(%d,)", len(v))
+		data = [][]float64{v}
+	case [][]float64:
+		if err := checkRect(op, v); err != nil {
+			return err
+		}
+		cols := 0
+		if len(v) > 0 {
+			cols = len(v[0])
+		}
+		shape = fmt.Sprintf("(%d, %d)", len(v), cols)
+		data = v
+		if opts.FortranOrder && len(v) > 0 {
+			data = T(v)
+		}
+	default:
+		s := "expected []float64, or [][]float64 but received type: %T"
+		return &ArgError{Op: op, Msg: fmt.Sprintf(s, v)}
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	descr := "<f8"
+	if opts.BigEndian {
+		order, descr = binary.BigEndian, ">f8"
+	}
+	fortran := "False"
+	if opts.FortranOrder {
+		fortran = "True"
+	}
+	header := fmt.Sprintf("{'descr': '%s', 'fortran_order': %s, 'shape': %s, }", descr, fortran, shape)
+	// The header is padded with spaces and ended with a newline, so that the
+	// data starts on a 64 byte boundary. Version 2.0 is only needed for headers
+	// which do not fit into 16 bits.
+	var buf bytes.Buffer
+	buf.WriteString(npyMagic)
+	if n := len(npyMagic) + 4 + len(header) + 1; n+(64-n%64)%64 < 1<<16 {
+		header += strings.Repeat(" ", (64-n%64)%64)
+		buf.Write([]byte{1, 0})
+		binary.Write(&buf, binary.LittleEndian, uint16(len(header)+1))
+	} else {
+		n += 2
+		header += strings.Repeat(" ", (64-n%64)%64)
+		buf.Write([]byte{2, 0})
+		binary.Write(&buf, binary.LittleEndian, uint32(len(header)+1))
+	}
+	buf.WriteString(header)
+	buf.WriteByte('\n')
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	var b []byte
+	for i := range data {
+		b = b[:0]
+		for _, x := range data[i] {
+			var e [8]byte
+			order.PutUint64(e[:], math.Float64bits(x))
+			b = append(b, e[:]...)
+		}
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+readFloats reads n float64s from r in the given byte order. The values are
+read in chunks of at most encodingChunk entries, so that memory is only
+allocated for data which is actually present, and truncated input fails before
+a buffer for all n values is allocated.
+*/
+func readFloats(r io.Reader, order binary.ByteOrder, n int) ([]float64, error) {
+	chunk := n
+	if chunk > encodingChunk {
+		chunk = encodingChunk
+	}
+	v := make([]float64, 0, chunk)
+	b := make([]byte, 8*chunk)
+	for len(v) < n {
+		k := n - len(v)
+		if k > chunk {
+			k = chunk
+		}
+		if _, err := io.ReadFull(r, b[:8*k]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		for i := 0; i < k; i++ {
+			v = append(v, math.Float64frombits(order.Uint64(b[8*i:])))
+		}
+	}
+	return v, nil
+}
+
+/*
+unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, for input which ends
+before a complete value could be read.
+*/
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
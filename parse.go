@@ -0,0 +1,281 @@
+package matf64
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+SyntaxError describes text which Parse could not read as a [][]float64. Line
+and Col are the 1-based position of the offending token in the text, with Col
+counted in bytes. SyntaxError wraps ErrFormat.
+*/
+type SyntaxError struct {
+	Line, Col int
+	Msg       string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("matf64.Parse(): line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Unwrap returns ErrFormat.
+func (e *SyntaxError) Unwrap() error { return ErrFormat }
+
+/*
+Parse reads a [][]float64 from text in the notation of MATLAB or NumPy. Rows
+are separated by semicolons or newlines, and entries by commas or spaces, with
+the whole optionally enclosed in brackets. Alternatively, each row may be
+enclosed in its own brackets. For example, all the following give the same
+[][]float64:
+
+	m, err := matf64.Parse("[1 2 3; 4 5 6]")
+	m, err = matf64.Parse("[[1, 2, 3], [4, 5, 6]]")
+	m, err = matf64.Parse(`
+		1 2 3
+		4 5 6
+	`)
+
+Entries are read with strconv.ParseFloat, so scientific notation, inf and nan
+are accepted in any case. All rows must have the same number of entries. A
+SyntaxError pointing at the problem is returned for text which can not be
+read. The output of Sprint can be read back with Parse, unless rows or columns
+were elided, so that
+
+	n, err := matf64.Parse(matf64.Sprint(m))
+
+gives n equal to m.
+*/
+func Parse(s string) ([][]float64, error) {
+	p := &parser{toks: lex(s)}
+	return p.parse()
+}
+
+/*
+token is a lexical token of the text read by Parse. Its kind is one of the
+characters "[],;" or '\n', or 'n' for a number.
+*/
+type token struct {
+	kind      byte
+	text      string
+	line, col int
+}
+
+/*
+lex splits s into tokens, ending with a token of kind 0 at the end of s.
+*/
+func lex(s string) []token {
+	var toks []token
+	line, col := 1, 1
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case ' ', '\t', '\r':
+			i++
+			col++
+		case '\n':
+			toks = append(toks, token{kind: '\n', text: "\n", line: line, col: col})
+			i++
+			line, col = line+1, 1
+		case '[', ']', ',', ';':
+			toks = append(toks, token{kind: c, text: string(c), line: line, col: col})
+			i++
+			col++
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\r\n[],;", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{kind: 'n', text: s[i:j], line: line, col: col})
+			col += j - i
+			i = j
+		}
+	}
+	return append(toks, token{line: line, col: col})
+}
+
+/*
+parser reads a [][]float64 from a list of tokens.
+*/
+type parser struct {
+	toks []token
+	pos  int
+	rows [][]float64
+	// start holds the first token of each row, to report rows of the wrong
+	// length.
+	start []token
+	// nested is set when each row is enclosed in its own brackets.
+	nested bool
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != 0 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().kind == '\n' {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(t token, format string, a ...interface{}) error {
+	return &SyntaxError{Line: t.line, Col: t.col, Msg: fmt.Sprintf(format, a...)}
+}
+
+/*
+describe returns a description of a token for error messages.
+*/
+func describe(t token) string {
+	switch t.kind {
+	case 0:
+		return "end of input"
+	case '\n':
+		return "newline"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+func (p *parser) parse() ([][]float64, error) {
+	p.skipNewlines()
+	var err error
+	if p.peek().kind != '[' {
+		err = p.parseRows(0)
+	} else {
+		p.next()
+		p.skipNewlines()
+		if p.peek().kind == '[' {
+			err = p.parseNested()
+		} else {
+			err = p.parseRows(']')
+		}
+		if err == nil {
+			p.next()
+			// A trailing semicolon, as ending a MATLAB statement, is ignored.
+			if p.peek().kind == ';' {
+				p.next()
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.skipNewlines()
+	if t := p.next(); t.kind != 0 {
+		return nil, p.errorf(t, "unexpected %s after the end of the matrix", describe(t))
+	}
+	for i := range p.rows {
+		if len(p.rows[i]) != len(p.rows[0]) {
+			s := "row %d has %d entries, but row 1 has %d"
+			return nil, p.errorf(p.start[i], s, i+1, len(p.rows[i]), len(p.rows[0]))
+		}
+	}
+	if p.rows == nil {
+		p.rows = [][]float64{}
+	}
+	return p.rows, nil
+}
+
+/*
+parseRows reads rows separated by semicolons or newlines, up to but excluding
+a token of the end kind.
+*/
+func (p *parser) parseRows(end byte) error {
+	for {
+		p.skipNewlines()
+		t := p.peek()
+		if t.kind == end {
+			return nil
+		}
+		row, err := p.parseEntries()
+		if err != nil {
+			return err
+		}
+		p.rows = append(p.rows, row)
+		p.start = append(p.start, t)
+		switch t := p.peek(); t.kind {
+		case ';', '\n':
+			p.next()
+		case end:
+		default:
+			return p.errorf(t, "unexpected %s", describe(t))
+		}
+	}
+}
+
+/*
+parseNested reads rows which are each enclosed in brackets, up to but
+excluding the closing bracket of the whole matrix.
+*/
+func (p *parser) parseNested() error {
+	p.nested = true
+	for {
+		t := p.next()
+		if t.kind != '[' {
+			return p.errorf(t, "expected \"[\" to start a row, but found %s", describe(t))
+		}
+		p.skipNewlines()
+		var row []float64
+		if p.peek().kind != ']' {
+			var err error
+			if row, err = p.parseEntries(); err != nil {
+				return err
+			}
+		}
+		if r := p.next(); r.kind != ']' {
+			return p.errorf(r, "expected \"]\" to end the row, but found %s", describe(r))
+		}
+		if row == nil {
+			row = []float64{}
+		}
+		p.rows = append(p.rows, row)
+		p.start = append(p.start, t)
+		p.skipNewlines()
+		if p.peek().kind == ',' {
+			p.next()
+			p.skipNewlines()
+		}
+		if p.peek().kind == ']' {
+			return nil
+		}
+	}
+}
+
+/*
+parseEntries reads a row of at least one number, separated by commas or spaces,
+stopping before any other token. Newlines are allowed within the rows of a
+nested matrix, since these are delimited by their brackets.
+*/
+func (p *parser) parseEntries() ([]float64, error) {
+	var row []float64
+	for {
+		t := p.next()
+		if t.kind != 'n' {
+			return nil, p.errorf(t, "expected a number, but found %s", describe(t))
+		}
+		x, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %q", t.text)
+		}
+		row = append(row, x)
+		if p.peek().kind == ',' {
+			p.next()
+			if p.nested {
+				p.skipNewlines()
+			}
+			continue
+		}
+		if p.nested {
+			p.skipNewlines()
+		}
+		if p.peek().kind != 'n' {
+			return row, nil
+		}
+	}
+}
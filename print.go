@@ -0,0 +1,365 @@
+package matf64
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Notation selects how Format writes numbers.
+*/
+type Notation int
+
+const (
+	// Auto uses scientific notation for a whole [][]float64 when its largest
+	// finite magnitude is at least 1e8, or its smallest non-zero magnitude is
+	// below 1e-4, as NumPy does, and fixed point notation otherwise.
+	Auto Notation = iota
+	// Fixed always uses fixed point notation, such as 12.5.
+	Fixed
+	// Scientific always uses scientific notation, such as 1.25e+01.
+	Scientific
+)
+
+/*
+Style selects the layout produced by Format.
+*/
+type Style int
+
+const (
+	// Plain lays the rows out as nested brackets, one row per line, like NumPy.
+	Plain Style = iota
+	// Markdown lays the rows out as a Markdown table, with the column indices
+	// as its header. A [][]float64 without entries is written as by Plain.
+	Markdown
+	// LaTeX lays the rows out as a LaTeX bmatrix environment.
+	LaTeX
+)
+
+/*
+Format describes how Sprint writes a [][]float64. Precision is the number of
+digits after the decimal point, or -1 for the fewest digits which read back
+to the exact same float64. If Threshold is positive, and the [][]float64 has
+more entries than it, any dimension longer than 2 * EdgeItems is elided, so
+that only its first and last EdgeItems rows or columns are written. For
+example:
+
+	f := matf64.Format{Precision: 2, Threshold: 100, EdgeItems: 2}
+	fmt.Println(f.Sprint(matf64.I(20)))
+
+writes the first and last 2 rows and columns of a 20x20 identity matrix with
+two decimals. The columns of the Plain and Markdown styles are aligned on
+their decimal points.
+*/
+type Format struct {
+	Precision int
+	Notation  Notation
+	Style     Style
+	Threshold int
+	EdgeItems int
+}
+
+/*
+DefaultFormat is used by Sprint. It writes numbers exactly, in the Plain
+style, and elides [][]float64s with more than 1000 entries down to 3 rows and
+columns at each end, like NumPy.
+*/
+var DefaultFormat = Format{Precision: -1, Threshold: 1000, EdgeItems: 3}
+
+/*
+Sprint returns a human readable representation of a [][]float64 using
+DefaultFormat, with aligned columns and one row per line. For example:
+
+	m := [][]float64{{1.0, -2.5}, {30.0, 4.25}}
+	fmt.Println(matf64.Sprint(m))
+
+prints
+
+	[[ 1 -2.5 ]
+	 [30  4.25]]
+
+The output can be read back with Parse, unless rows or columns were elided.
+*/
+func Sprint(m [][]float64) string {
+	return DefaultFormat.Sprint(m)
+}
+
+/*
+Sprint returns the representation of a [][]float64 described by f. The
+[][]float64 must not be jagged.
+*/
+func (f Format) Sprint(m [][]float64) string {
+	if debug {
+		check(checkRect("Sprint()", m))
+	}
+	rows, cols := len(m), 0
+	if rows > 0 {
+		cols = len(m[0])
+	}
+	ri := f.kept(rows, rows*cols)
+	ci := f.kept(cols, rows*cols)
+	sci := f.Notation == Scientific || (f.Notation == Auto && useScientific(m, ri, ci))
+	// An elided row is a nil row of cells, and an elided column an empty cell.
+	cells := make([][]string, len(ri))
+	for a, i := range ri {
+		if i < 0 {
+			continue
+		}
+		cells[a] = make([]string, len(ci))
+		for b, j := range ci {
+			if j >= 0 {
+				cells[a][b] = f.number(m[i][j], sci)
+			}
+		}
+	}
+	var sb strings.Builder
+	switch f.Style {
+	case Markdown:
+		if rows == 0 || cols == 0 {
+			// A Markdown table needs at least one column, so an empty
+			// [][]float64 is written as in the Plain style.
+			f.Style = Plain
+			return f.Sprint(m)
+		}
+		header := make([]string, len(ci))
+		for b, j := range ci {
+			if j >= 0 {
+				header[b] = strconv.Itoa(j)
+			}
+		}
+		align(append([][]string{header}, cells...), "...")
+		for b := range header {
+			header[b] = pad(strings.TrimSpace(header[b]), len(header[b]), 0)
+		}
+		sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+		sb.WriteString("|")
+		for b := range header {
+			sb.WriteString(strings.Repeat("-", len(header[b])+1) + ":|")
+		}
+		sb.WriteString("\n")
+		for a := range cells {
+			if cells[a] == nil {
+				cells[a] = make([]string, len(ci))
+				for b := range header {
+					cells[a][b] = pad("...", len(header[b]), 0)
+				}
+			}
+			sb.WriteString("| " + strings.Join(cells[a], " | ") + " |\n")
+		}
+	case LaTeX:
+		sb.WriteString("\\begin{bmatrix}\n")
+		for a := range cells {
+			line := make([]string, len(ci))
+			for b := range ci {
+				switch {
+				case cells[a] == nil && ci[b] < 0:
+					line[b] = "\\ddots"
+				case cells[a] == nil:
+					line[b] = "\\vdots"
+				case ci[b] < 0:
+					line[b] = "\\cdots"
+				default:
+					line[b] = latexNumber(cells[a][b])
+				}
+			}
+			sb.WriteString(strings.Join(line, " & "))
+			if a < len(cells)-1 {
+				sb.WriteString(" \\\\")
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\\end{bmatrix}")
+	default:
+		if rows == 0 {
+			return "[]"
+		}
+		align(cells, "...")
+		sb.WriteString("[")
+		for a := range cells {
+			if a > 0 {
+				sb.WriteString(" ")
+			}
+			if cells[a] == nil {
+				sb.WriteString("...\n")
+				continue
+			}
+			sb.WriteString("[" + strings.Join(cells[a], " ") + "]")
+			if a < len(cells)-1 {
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString("]")
+	}
+	return sb.String()
+}
+
+/*
+kept returns the indices of a dimension of length n which are written for a
+[][]float64 with size entries, with -1 marking where the others were elided.
+*/
+func (f Format) kept(n, size int) []int {
+	idx := make([]int, 0, n)
+	if f.Threshold > 0 && size > f.Threshold && f.EdgeItems >= 0 && n > 2*f.EdgeItems {
+		for i := 0; i < f.EdgeItems; i++ {
+			idx = append(idx, i)
+		}
+		idx = append(idx, -1)
+		for i := n - f.EdgeItems; i < n; i++ {
+			idx = append(idx, i)
+		}
+		return idx
+	}
+	for i := 0; i < n; i++ {
+		idx = append(idx, i)
+	}
+	return idx
+}
+
+/*
+number formats a single value following f, in scientific notation if sci is
+true.
+*/
+func (f Format) number(x float64, sci bool) string {
+	if sci {
+		return strconv.FormatFloat(x, 'e', f.Precision, 64)
+	}
+	return strconv.FormatFloat(x, 'f', f.Precision, 64)
+}
+
+/*
+useScientific reports whether the written entries of m, in the rows ri and
+columns ci, span a range of magnitudes better written in scientific notation.
+*/
+func useScientific(m [][]float64, ri, ci []int) bool {
+	big, small := 0.0, math.Inf(1)
+	for _, i := range ri {
+		for _, j := range ci {
+			if i < 0 || j < 0 {
+				continue
+			}
+			x := math.Abs(m[i][j])
+			if x == 0.0 || math.IsInf(x, 0) || math.IsNaN(x) {
+				continue
+			}
+			if x > big {
+				big = x
+			}
+			if x < small {
+				small = x
+			}
+		}
+	}
+	return big >= 1e8 || small < 1e-4
+}
+
+/*
+align pads the cells of each column in place, so that they all have the same
+width and the numbers line up on their decimal points, while cells such as NaN
+and Inf are right aligned. Empty cells are replaced by the marker, and nil rows
+are skipped.
+*/
+func align(cells [][]string, marker string) {
+	cols := 0
+	for a := range cells {
+		if len(cells[a]) > cols {
+			cols = len(cells[a])
+		}
+	}
+	for b := 0; b < cols; b++ {
+		intw, fracw, otherw := 0, 0, 0
+		for a := range cells {
+			if cells[a] == nil {
+				continue
+			}
+			if cells[a][b] == "" {
+				cells[a][b] = marker
+			}
+			s := cells[a][b]
+			if !isNumber(s) {
+				if len(s) > otherw {
+					otherw = len(s)
+				}
+				continue
+			}
+			i := point(s)
+			if i > intw {
+				intw = i
+			}
+			if len(s)-i > fracw {
+				fracw = len(s) - i
+			}
+		}
+		if otherw > intw+fracw {
+			intw = otherw - fracw
+		}
+		for a := range cells {
+			if cells[a] == nil {
+				continue
+			}
+			s := cells[a][b]
+			if !isNumber(s) {
+				cells[a][b] = pad(s, intw+fracw, 0)
+				continue
+			}
+			i := point(s)
+			cells[a][b] = pad(s, intw-i+len(s), fracw-(len(s)-i))
+		}
+	}
+}
+
+/*
+isNumber reports whether a formatted cell holds a finite number, as opposed to
+NaN, an infinity or an elision marker.
+*/
+func isNumber(s string) bool {
+	return strings.IndexAny(s, "0123456789") >= 0
+}
+
+/*
+point returns the length of the integer part of a formatted number, which is
+the position of its decimal point or exponent, or its full length otherwise.
+*/
+func point(s string) int {
+	if i := strings.IndexAny(s, ".e"); i >= 0 {
+		return i
+	}
+	return len(s)
+}
+
+/*
+pad left pads s with spaces up to a width of left, and then adds right spaces
+after it.
+*/
+func pad(s string, left, right int) string {
+	if n := left - len(s); n > 0 {
+		s = strings.Repeat(" ", n) + s
+	}
+	if right > 0 {
+		s += strings.Repeat(" ", right)
+	}
+	return s
+}
+
+/*
+latexNumber converts a formatted number into LaTeX math.
+*/
+func latexNumber(s string) string {
+	switch s {
+	case "NaN":
+		return "\\mathrm{NaN}"
+	case "+Inf":
+		return "\\infty"
+	case "-Inf":
+		return "-\\infty"
+	}
+	i := strings.IndexByte(s, 'e')
+	if i < 0 {
+		return s
+	}
+	exp, err := strconv.Atoi(s[i+1:])
+	if err != nil {
+		return s
+	}
+	return s[:i] + " \\times 10^{" + strconv.Itoa(exp) + "}"
+}
@@ -0,0 +1,191 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+QR computes the thin QR decomposition of a [][]float64 using Householder
+reflections. For a m by n [][]float64 with k = min(m, n), q is a m by k
+[][]float64 with orthonormal columns, and r is a k by n upper triangular
+[][]float64, such that
+
+	m = Dot(q, r)
+
+The passed [][]float64 is assumed to be non-jagged, and is not mutated in this
+function.
+*/
+func QR(m [][]float64) (q, r [][]float64) {
+	if debug {
+		check(checkRect("QR()", m))
+	}
+	qr, tau, perm := householderQR(m, false)
+	k := len(tau)
+	r = New(k, len(perm))
+	for i := range r {
+		for j := i; j < len(r[i]); j++ {
+			r[i][j] = qr[i][j]
+		}
+	}
+	q = New(len(qr), k)
+	for i := 0; i < k; i++ {
+		q[i][i] = 1.0
+	}
+	for h := k - 1; h >= 0; h-- {
+		for j := 0; j < k; j++ {
+			s := q[h][j]
+			for i := h + 1; i < len(qr); i++ {
+				s += qr[i][h] * q[i][j]
+			}
+			s *= tau[h]
+			q[h][j] -= s
+			for i := h + 1; i < len(qr); i++ {
+				q[i][j] -= s * qr[i][h]
+			}
+		}
+	}
+	return q, r
+}
+
+/*
+LeastSquares finds the x which minimizes the euclidean norm of a * x - b, where
+a is a m by n [][]float64 and b is a []float64 with m entries. This is the usual
+way of fitting a linear model to a tall design matrix, for example:
+
+	x, res, rank := matf64.LeastSquares(a, b)
+
+Along with the coefficients x, LeastSquares returns the residuals b - a * x, and
+the numerical rank of a. The solution is computed from a Householder QR
+decomposition with column pivoting, which avoids forming the poorly conditioned
+normal equations. If a is rank deficient, the basic solution is returned, where
+the coefficients of the dependent columns are set to 0.0.
+
+The passed [][]float64 is assumed to be non-jagged. The passed arguments are not
+mutated by this function.
+*/
+func LeastSquares(a [][]float64, b []float64) (x, res []float64, rank int) {
+	if debug {
+		check(checkRect("LeastSquares()", a))
+	}
+	if len(b) != len(a) {
+		s := "In matf64.%s the []float64 has %d entries, but the [][]float64 has %d rows."
+		s = fmt.Sprintf(s, "LeastSquares()", len(b), len(a))
+		panic(s)
+	}
+	qr, tau, perm := householderQR(a, true)
+	cols := len(perm)
+	c := make([]float64, len(b))
+	copy(c, b)
+	for h := range tau {
+		s := c[h]
+		for i := h + 1; i < len(qr); i++ {
+			s += qr[i][h] * c[i]
+		}
+		s *= tau[h]
+		c[h] -= s
+		for i := h + 1; i < len(qr); i++ {
+			c[i] -= s * qr[i][h]
+		}
+	}
+	if len(tau) > 0 {
+		tol := float64(len(qr)) * eps * math.Abs(qr[0][0])
+		if cols > len(qr) {
+			tol = float64(cols) * eps * math.Abs(qr[0][0])
+		}
+		for rank < len(tau) && math.Abs(qr[rank][rank]) > tol {
+			rank++
+		}
+	}
+	z := make([]float64, rank)
+	for i := rank - 1; i >= 0; i-- {
+		z[i] = c[i]
+		for j := i + 1; j < rank; j++ {
+			z[i] -= qr[i][j] * z[j]
+		}
+		z[i] /= qr[i][i]
+	}
+	x = make([]float64, cols)
+	for i := range z {
+		x[perm[i]] = z[i]
+	}
+	res = make([]float64, len(b))
+	for i := range a {
+		res[i] = b[i]
+		for j := range a[i] {
+			res[i] -= a[i][j] * x[j]
+		}
+	}
+	return x, res, rank
+}
+
+/*
+householderQR computes the packed Householder QR decomposition of m. The upper
+triangle of the returned [][]float64 holds r, while the Householder vector of
+the h-th reflection, with its implicit leading 1.0, is stored below the
+diagonal of column h, and its scaling factor in tau[h]. If pivot is true, the
+column of largest remaining norm is moved to the front at each step, and the
+final column order is returned in perm.
+*/
+func householderQR(m [][]float64, pivot bool) (qr [][]float64, tau []float64, perm []int) {
+	qr = Clone(m)
+	rows, cols := len(qr), 0
+	if rows > 0 {
+		cols = len(qr[0])
+	}
+	k := rows
+	if cols < k {
+		k = cols
+	}
+	tau = make([]float64, k)
+	perm = make([]int, cols)
+	for j := range perm {
+		perm[j] = j
+	}
+	for h := 0; h < k; h++ {
+		if pivot {
+			best, bestNorm := h, -1.0
+			for j := h; j < cols; j++ {
+				norm := 0.0
+				for i := h; i < rows; i++ {
+					norm += qr[i][j] * qr[i][j]
+				}
+				if norm > bestNorm {
+					best, bestNorm = j, norm
+				}
+			}
+			if best != h {
+				for i := range qr {
+					qr[i][h], qr[i][best] = qr[i][best], qr[i][h]
+				}
+				perm[h], perm[best] = perm[best], perm[h]
+			}
+		}
+		norm := 0.0
+		for i := h; i < rows; i++ {
+			norm = math.Hypot(norm, qr[i][h])
+		}
+		if norm == 0.0 {
+			continue
+		}
+		alpha := qr[h][h]
+		beta := -math.Copysign(norm, alpha)
+		tau[h] = (beta - alpha) / beta
+		for i := h + 1; i < rows; i++ {
+			qr[i][h] /= alpha - beta
+		}
+		qr[h][h] = beta
+		for j := h + 1; j < cols; j++ {
+			s := qr[h][j]
+			for i := h + 1; i < rows; i++ {
+				s += qr[i][h] * qr[i][j]
+			}
+			s *= tau[h]
+			qr[h][j] -= s
+			for i := h + 1; i < rows; i++ {
+				qr[i][j] -= s * qr[i][h]
+			}
+		}
+	}
+	return qr, tau, perm
+}
@@ -0,0 +1,131 @@
+package matf64
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+/*
+Rand is a source of random [][]float64s and []float64s. Unlike the top level
+RandMat and RandVec, which use the global source of the math/rand package, a
+Rand created with NewRand produces the same sequence of values for the same
+seed, so that simulations can be reproduced. For example:
+
+	r := matf64.NewRand(42)
+	m := r.RandMat(3, 4)
+
+A Rand is safe to use from multiple goroutines, with each call holding the
+Rand until it is done, so that every [][]float64 is filled by consecutive
+values. For reproducible results across goroutines, give each goroutine its
+own Rand.
+*/
+type Rand struct {
+	mu  sync.Mutex
+	src *rand.Rand
+}
+
+/*
+NewRand creates a Rand seeded with the passed value.
+*/
+func NewRand(seed int64) *Rand {
+	return &Rand{src: rand.New(rand.NewSource(seed))}
+}
+
+/*
+global is used by the top level functions. It has no source of its own, and
+uses the global source of the math/rand package instead.
+*/
+var global = &Rand{}
+
+/*
+float64 returns a random number in [0, 1). The caller must hold r.mu.
+*/
+func (r *Rand) float64() float64 {
+	if r.src == nil {
+		return rand.Float64()
+	}
+	return r.src.Float64()
+}
+
+/*
+normFloat64 returns a standard normally distributed number. The caller must
+hold r.mu.
+*/
+func (r *Rand) normFloat64() float64 {
+	if r.src == nil {
+		return rand.NormFloat64()
+	}
+	return r.src.NormFloat64()
+}
+
+/*
+expFloat64 returns an exponentially distributed number with rate 1. The caller
+must hold r.mu.
+*/
+func (r *Rand) expFloat64() float64 {
+	if r.src == nil {
+		return rand.ExpFloat64()
+	}
+	return r.src.ExpFloat64()
+}
+
+/*
+RandMat creates a x by y [][]float64 with the entries set to random numbers in
+the range [0, 1), drawn from r. The range can be changed in the same way as for
+the top level RandMat.
+*/
+func (r *Rand) RandMat(x, y int, args ...float64) [][]float64 {
+	if debug {
+		check(checkDims("RandMat()", x, y))
+	}
+	from, to := randRange("RandMat()", args)
+	m := New(x, y)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = r.float64()*(to-from) + from
+		}
+	}
+	return m
+}
+
+/*
+RandVec returns a []float64 with the entries set to random numbers in the
+range [0, 1), drawn from r. The range can be changed in the same way as for
+the top level RandVec.
+*/
+func (r *Rand) RandVec(size int, args ...float64) []float64 {
+	if debug {
+		check(checkDims("RandVec()", size))
+	}
+	from, to := randRange("RandVec()", args)
+	v := make([]float64, size)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range v {
+		v[i] = r.float64()*(to-from) + from
+	}
+	return v
+}
+
+/*
+randRange returns the range [from, to) selected by the optional arguments of
+RandMat and RandVec, which are either nothing for [0, 1), the upper bound, or
+both bounds.
+*/
+func randRange(caller string, args []float64) (from, to float64) {
+	switch len(args) {
+	case 0:
+		return 0.0, 1.0
+	case 1:
+		return 0.0, args[0]
+	case 2:
+		return args[0], args[1]
+	default:
+		s := "In matf64.%s expected 0-2 float64s for the range, but recieved %d"
+		s = fmt.Sprintf(s, caller, len(args))
+		panic(s)
+	}
+}
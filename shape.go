@@ -0,0 +1,108 @@
+package matf64
+
+/*
+Shape returns the number of rows and columns of a [][]float64. If the rows do
+not all have the same length, a JaggedError naming the first ragged row is
+returned. For example:
+
+	rows, cols, err := matf64.Shape(m)
+
+An empty [][]float64 has 0 rows and 0 columns.
+*/
+func Shape(m [][]float64) (rows, cols int, err error) {
+	return shape("Shape()", m)
+}
+
+/*
+IsRect checks to see if a [][]float64 is rectangular, meaning that all of its
+rows have the same number of entries.
+*/
+func IsRect(m [][]float64) bool {
+	_, _, err := shape("IsRect()", m)
+	return err == nil
+}
+
+/*
+check panics with err if it is not nil. It is used by the checked mode, where
+calls to it are guarded by the debug constant, so that they are compiled away
+in normal builds.
+*/
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+/*
+checkRect returns a JaggedError naming op for the first of the passed
+[][]float64s which is jagged.
+*/
+func checkRect(op string, ms ...[][]float64) error {
+	for _, m := range ms {
+		if _, _, err := shape(op, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+checkDot validates that m and n are rectangular, and that they can be
+multiplied together with Dot.
+*/
+func checkDot(op string, m, n [][]float64) error {
+	_, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	nRows, nCols, err := shape(op, n)
+	if err != nil {
+		return err
+	}
+	if nRows != cols || nRows == 0 {
+		return &ShapeError{Op: op, Rows: nRows, Cols: nCols, WantRows: cols, WantCols: -1}
+	}
+	return nil
+}
+
+/*
+checkIndex validates that m is rectangular and that x, which may be negative,
+selects an existing row when axis is 0, or an existing column when axis is 1.
+*/
+func checkIndex(op string, m [][]float64, axis, x int) error {
+	rows, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	if axis == 0 {
+		_, err = index(op, x, rows)
+	} else {
+		_, err = index(op, x, cols)
+	}
+	return err
+}
+
+/*
+checkLen validates that the []float64 v has n entries.
+*/
+func checkLen(op string, v []float64, n int) error {
+	if len(v) != n {
+		return &ShapeError{Op: op, Rows: 1, Cols: len(v), WantRows: 1, WantCols: n}
+	}
+	return nil
+}
+
+/*
+checkSquareShape validates that m is a square [][]float64, returning a
+JaggedError if it is jagged and a ShapeError if it is not square.
+*/
+func checkSquareShape(op string, m [][]float64) error {
+	rows, cols, err := shape(op, m)
+	if err != nil {
+		return err
+	}
+	if rows != cols {
+		return &ShapeError{Op: op, Rows: rows, Cols: cols, WantRows: rows, WantCols: rows}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package matf64
+
+import "fmt"
+
+/*
+Slice returns the block of a [][]float64 made of the rows r0 up to, but
+excluding, r1, and the columns c0 up to, but excluding, c1, much like
+m[r0:r1, c0:c1] in NumPy. For example:
+
+	m := [][]float64{{1.0, 2.0, 3.0}, {4.0, 5.0, 6.0}, {7.0, 8.0, 9.0}}
+	matf64.Slice(m, 1, 3, 0, 2) // [[4.0, 5.0], [7.0, 8.0]]
+
+As with Row and Col, negative indices count back from the end, so that
+
+	matf64.Slice(m, -2, 3, 1, 3) // [[5.0, 6.0], [8.0, 9.0]]
+
+The rows of the result share their entries with m, so changes made to one are
+seen in the other. For example, after
+
+	s := matf64.Slice(m, 0, 2, 0, 2)
+	matf64.MultScalar(s, 10.0)
+
+the top left 2 by 2 block of m is multiplied by 10. The capacity of each row is
+limited to the block, so appending to a row of the result copies it rather
+than overwriting the following entries of m. Use SliceCopy for an independent
+[][]float64.
+*/
+func Slice(m [][]float64, r0, r1, c0, c1 int) [][]float64 {
+	r0, r1, c0, c1 = sliceBounds("Slice()", m, r0, r1, c0, c1)
+	s := make([][]float64, r1-r0)
+	for i := range s {
+		s[i] = m[r0+i][c0:c1:c1]
+	}
+	return s
+}
+
+/*
+SliceCopy returns a copy of the block of a [][]float64 selected as in Slice.
+The original [][]float64 is not mutated in this function, and is not affected
+by changes to the result.
+*/
+func SliceCopy(m [][]float64, r0, r1, c0, c1 int) [][]float64 {
+	r0, r1, c0, c1 = sliceBounds("SliceCopy()", m, r0, r1, c0, c1)
+	s := New(r1-r0, c1-c0)
+	for i := range s {
+		copy(s[i], m[r0+i][c0:c1])
+	}
+	return s
+}
+
+/*
+sliceBounds resolves the possibly negative bounds passed to Slice and
+SliceCopy into indices of m, panicking with a message naming the caller if
+they do not select a block of m.
+*/
+func sliceBounds(caller string, m [][]float64, r0, r1, c0, c1 int) (int, int, int, int) {
+	if debug {
+		check(checkRect(caller, m))
+	}
+	rows, cols := len(m), 0
+	if rows > 0 {
+		cols = len(m[0])
+	}
+	lo, hi, ok := resolveRange(r0, r1, rows)
+	if !ok {
+		s := "In matf64.%s the rows %d to %d are out of range for a [][]float64 with %d rows."
+		panic(fmt.Sprintf(s, caller, r0, r1, rows))
+	}
+	clo, chi, ok := resolveRange(c0, c1, cols)
+	if !ok {
+		s := "In matf64.%s the columns %d to %d are out of range for a [][]float64 with %d columns."
+		panic(fmt.Sprintf(s, caller, c0, c1, cols))
+	}
+	return lo, hi, clo, chi
+}
+
+/*
+resolveRange adds n to the negative bounds of the range [lo, hi), and reports
+whether the resolved range lies within [0, n].
+*/
+func resolveRange(lo, hi, n int) (int, int, bool) {
+	if lo < 0 {
+		lo += n
+	}
+	if hi < 0 {
+		hi += n
+	}
+	return lo, hi, lo >= 0 && lo <= hi && hi <= n
+}
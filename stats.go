@@ -0,0 +1,355 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+Interpolation selects how Quantile and Percentile pick a value when the
+requested quantile falls between two entries of the sorted data. Each method
+is described below in terms of the data sorted in ascending order into x, with
+the quantile q located at the fractional position q * (len(x) - 1), between
+the indices i and i + 1.
+*/
+type Interpolation int
+
+const (
+	// Linear interpolates linearly between x[i] and x[i+1].
+	Linear Interpolation = iota
+	// Lower picks x[i].
+	Lower
+	// Higher picks x[i+1].
+	Higher
+	// Nearest picks whichever of x[i] and x[i+1] is closest, rounding half way
+	// positions to the even index.
+	Nearest
+	// Midpoint picks the average of x[i] and x[i+1].
+	Midpoint
+)
+
+/*
+Var returns the population variance of all elements in a [][]float64, which is
+the average of the squared deviations from the mean. For example:
+
+	m := [][]float64{{1.0, 2.0}, {3.0, 4.0}}
+	matf64.Var(m) // 1.25
+
+As with Sum, the variance of a specific row or column can be found by passing
+two additional integers, the first being 0 for a row or 1 for a column, and
+the second the (possibly negative) index of that row or column:
+
+	matf64.Var(m, 1, 0) // 1.0
+
+The original [][]float64 is not mutated in this function.
+*/
+func Var(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Var()", m, args)
+	return variance(v, 0)
+}
+
+/*
+SampleVar returns the sample variance of all elements in a [][]float64, or of a
+row or column selected as in Var. This is the unbiased estimator, which divides
+the sum of the squared deviations by one less than the number of elements.
+*/
+func SampleVar(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("SampleVar()", m, args)
+	return variance(v, 1)
+}
+
+/*
+Std returns the population standard deviation of all elements in a
+[][]float64, or of a row or column selected as in Var.
+*/
+func Std(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Std()", m, args)
+	return math.Sqrt(variance(v, 0))
+}
+
+/*
+SampleStd returns the sample standard deviation of all elements in a
+[][]float64, or of a row or column selected as in Var. It is the square root
+of SampleVar.
+*/
+func SampleStd(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("SampleStd()", m, args)
+	return math.Sqrt(variance(v, 1))
+}
+
+/*
+Median returns the median of all elements in a [][]float64, or of a row or
+column selected as in Var. For an even number of elements, this is the average
+of the two middle elements. The original [][]float64 is not mutated in this
+function.
+*/
+func Median(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Median()", m, args)
+	return quantile("Median()", v, 0.5, Midpoint)
+}
+
+/*
+Quantile returns the q-th quantile of all elements in a [][]float64, where q is
+between 0 and 1, inclusive. The method decides how to pick a value which falls
+between two elements, see Interpolation. For example, the upper quartile of
+the last column of a [][]float64 is given by:
+
+	matf64.Quantile(m, 0.75, matf64.Linear, 1, -1)
+
+where, as with Var, the two optional integers select a row or column. The
+result is NaN if any of the considered elements are NaN. The original
+[][]float64 is not mutated in this function.
+*/
+func Quantile(m [][]float64, q float64, method Interpolation, args ...int) float64 {
+	v, _ := selectValues("Quantile()", m, args)
+	return quantile("Quantile()", v, q, method)
+}
+
+/*
+Percentile returns the p-th percentile of all elements in a [][]float64, where
+p is between 0 and 100, inclusive. It is otherwise the same as Quantile.
+*/
+func Percentile(m [][]float64, p float64, method Interpolation, args ...int) float64 {
+	v, _ := selectValues("Percentile()", m, args)
+	return quantile("Percentile()", v, p/100.0, method)
+}
+
+/*
+Min returns the smallest element in a [][]float64, or in a row or column
+selected as in Var. The result is NaN if any of the considered elements are
+NaN, or if there are none.
+*/
+func Min(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Min()", m, args)
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	min := v[0]
+	for i := range v {
+		min = math.Min(min, v[i])
+	}
+	return min
+}
+
+/*
+Max returns the largest element in a [][]float64, or in a row or column
+selected as in Var. The result is NaN if any of the considered elements are
+NaN, or if there are none.
+*/
+func Max(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Max()", m, args)
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	max := v[0]
+	for i := range v {
+		max = math.Max(max, v[i])
+	}
+	return max
+}
+
+/*
+ArgMin returns the row and column of the smallest element in a [][]float64, or
+in a row or column selected as in Var. The indices are always those of m
+itself, so for example:
+
+	row, col := matf64.ArgMin(m, 0, -1)
+
+gives row == len(m) - 1. If the smallest value occurs more than once, the first
+occurrence in row major order is returned. A NaN is considered smaller than
+any other value, and -1, -1 is returned if there are no elements.
+*/
+func ArgMin(m [][]float64, args ...int) (row, col int) {
+	v, pos := selectValues("ArgMin()", m, args)
+	return argBest(v, pos, func(a, b float64) bool { return a < b })
+}
+
+/*
+ArgMax returns the row and column of the largest element in a [][]float64, or
+in a row or column selected as in Var, following the same rules as ArgMin.
+*/
+func ArgMax(m [][]float64, args ...int) (row, col int) {
+	v, pos := selectValues("ArgMax()", m, args)
+	return argBest(v, pos, func(a, b float64) bool { return a > b })
+}
+
+/*
+Mode returns the most frequent element in a [][]float64, or in a row or column
+selected as in Var. If several elements are equally frequent, the smallest of
+them is returned. NaN elements are never counted, and NaN is returned if there
+are no other elements.
+*/
+func Mode(m [][]float64, args ...int) float64 {
+	v, _ := selectValues("Mode()", m, args)
+	sort.Float64s(v)
+	mode, best := math.NaN(), 0
+	for i := 0; i < len(v); {
+		j := i + 1
+		for j < len(v) && v[j] == v[i] {
+			j++
+		}
+		if !math.IsNaN(v[i]) && j-i > best {
+			mode, best = v[i], j-i
+		}
+		i = j
+	}
+	return mode
+}
+
+/*
+selectValues returns a copy of the elements of m considered by the statistics
+functions: all of them, or the row or column picked by the optional (axis,
+index) arguments in the same way as Sum. It also returns a function mapping
+the position of an element in the copy back to its row and column in m. The
+name of the calling function is used in the panic messages.
+*/
+func selectValues(caller string, m [][]float64, args []int) ([]float64, func(int) (int, int)) {
+	if debug {
+		check(checkAxisArgs(caller, m, args))
+	}
+	switch len(args) {
+	case 0:
+		v := Flatten(m)
+		return v, func(k int) (int, int) {
+			for i := range m {
+				if k < len(m[i]) {
+					return i, k
+				}
+				k -= len(m[i])
+			}
+			return -1, -1
+		}
+	case 2:
+		x := args[1]
+		switch args[0] {
+		case 0:
+			if x < 0 {
+				x += len(m)
+			}
+			return Row(m, x), func(k int) (int, int) { return x, k }
+		case 1:
+			if x < 0 {
+				x += len(m[0])
+			}
+			return Col(m, x), func(k int) (int, int) { return k, x }
+		default:
+			s := "In matf64.%s the first argument after the [][]float64 determines the axis.\n"
+			s += "It must be 0 for row, or 1 for column, but %d was passed."
+			s = fmt.Sprintf(s, caller, args[0])
+			panic(s)
+		}
+	default:
+		s := "In matf64.%s expected 0 or 2 arguments after the [][]float64 \n"
+		s += "but received %d"
+		s = fmt.Sprintf(s, caller, len(args))
+		panic(s)
+	}
+}
+
+/*
+variance returns the sum of the squared deviations of v from its mean, divided
+by len(v) - ddof.
+*/
+func variance(v []float64, ddof int) float64 {
+	if len(v)-ddof <= 0 {
+		return math.NaN()
+	}
+	mean := 0.0
+	for i := range v {
+		mean += v[i]
+	}
+	mean /= float64(len(v))
+	ss := 0.0
+	for i := range v {
+		d := v[i] - mean
+		ss += d * d
+	}
+	return ss / float64(len(v)-ddof)
+}
+
+/*
+quantile returns the q-th quantile of v, sorting v in place.
+*/
+func quantile(caller string, v []float64, q float64, method Interpolation) float64 {
+	if q < 0.0 || q > 1.0 || math.IsNaN(q) {
+		s := "In matf64.%s the quantile must be between 0 and 1 (or the percentile between\n"
+		s += "0 and 100), but %v was passed."
+		s = fmt.Sprintf(s, caller, q)
+		panic(s)
+	}
+	if len(v) == 0 {
+		return math.NaN()
+	}
+	for i := range v {
+		if math.IsNaN(v[i]) {
+			return math.NaN()
+		}
+	}
+	sort.Float64s(v)
+	pos := q * float64(len(v)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	switch method {
+	case Linear:
+		a, b, f := v[lo], v[hi], pos-float64(lo)
+		switch {
+		case lo == hi || a == b:
+			// Avoids 0 * (Inf - Inf) for an infinite element.
+			return a
+		case math.IsInf(a, 0) && math.IsInf(b, 0):
+			// There is nothing between -Inf and +Inf.
+			return math.NaN()
+		case math.IsInf(a, 0):
+			return a
+		case math.IsInf(b, 0):
+			return b
+		}
+		if d := b - a; !math.IsInf(d, 0) {
+			return a + f*d
+		}
+		// The difference of two huge values of opposite signs overflows.
+		return a*(1.0-f) + b*f
+	case Lower:
+		return v[lo]
+	case Higher:
+		return v[hi]
+	case Nearest:
+		return v[int(math.RoundToEven(pos))]
+	case Midpoint:
+		if lo == hi {
+			return v[lo]
+		}
+		mid := (v[lo] + v[hi]) / 2.0
+		if math.IsInf(mid, 0) && !math.IsInf(v[lo], 0) && !math.IsInf(v[hi], 0) {
+			// The sum of two huge values of the same sign overflows.
+			return v[lo]/2.0 + v[hi]/2.0
+		}
+		return mid
+	default:
+		s := "In matf64.%s received unknown interpolation method %d."
+		s = fmt.Sprintf(s, caller, method)
+		panic(s)
+	}
+}
+
+/*
+argBest returns the row and column in m of the first element of v for which
+better returns true against all others, treating NaN as best.
+*/
+func argBest(v []float64, pos func(int) (int, int), better func(a, b float64) bool) (int, int) {
+	if len(v) == 0 {
+		return -1, -1
+	}
+	best := 0
+	for k := range v {
+		if math.IsNaN(v[k]) {
+			best = k
+			break
+		}
+		if better(v[k], v[best]) {
+			best = k
+		}
+	}
+	return pos(best)
+}
@@ -0,0 +1,218 @@
+package matf64
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+RandOrthogonal creates a random n by n orthogonal [][]float64, distributed
+uniformly over all orthogonal matrices (the Haar measure), using the global
+source of the math/rand package.
+*/
+func RandOrthogonal(n int) [][]float64 {
+	return global.RandOrthogonal(n)
+}
+
+/*
+RandSPD creates a random n by n symmetric positive definite [][]float64 with
+the passed condition number, using the global source of the math/rand package.
+*/
+func RandSPD(n int, cond float64) [][]float64 {
+	return global.RandSPD(n, cond)
+}
+
+/*
+RandDiagDominant creates a random n by n strictly diagonally dominant
+[][]float64, using the global source of the math/rand package.
+*/
+func RandDiagDominant(n int) [][]float64 {
+	return global.RandDiagDominant(n)
+}
+
+/*
+RandLowRank creates a random x by y [][]float64 of the passed rank, using the
+global source of the math/rand package.
+*/
+func RandLowRank(x, y, rank int) [][]float64 {
+	return global.RandLowRank(x, y, rank)
+}
+
+/*
+RandBanded creates a random x by y [][]float64 with lower sub-diagonals and
+upper super-diagonals, using the global source of the math/rand package. The
+range of the entries can be changed as for RandMat.
+*/
+func RandBanded(x, y, lower, upper int, args ...float64) [][]float64 {
+	return global.RandBanded(x, y, lower, upper, args...)
+}
+
+/*
+RandOrthogonal creates a random n by n orthogonal [][]float64, drawn from r.
+It is the q factor of the QR decomposition of a [][]float64 of standard normal
+entries, with the signs of its columns fixed so that r has a positive
+diagonal. Without this fix, the result would not be uniformly distributed (see
+Mezzadri, "How to generate random matrices from the classical compact groups",
+2007).
+*/
+func (r *Rand) RandOrthogonal(n int) [][]float64 {
+	if debug {
+		check(checkDims("RandOrthogonal()", n))
+	}
+	if n == 0 {
+		return [][]float64{}
+	}
+	r.mu.Lock()
+	g := r.normals(n, n)
+	r.mu.Unlock()
+	q, rr := QR(g)
+	for j := 0; j < n; j++ {
+		if rr[j][j] < 0.0 {
+			for i := range q {
+				q[i][j] = -q[i][j]
+			}
+		}
+	}
+	return q
+}
+
+/*
+RandSPD creates a random n by n symmetric positive definite [][]float64 drawn
+from r, whose condition number is cond. It is built as Q D Q^T, where Q is
+from RandOrthogonal, and the eigenvalues in D are spaced logarithmically from
+1 down to 1 / cond. For example:
+
+	a := r.RandSPD(100, 1e6)
+
+gives a [][]float64 which is hard, but possible, to solve accurately. The
+result is exactly symmetric. It panics if cond is less than 1.
+*/
+func (r *Rand) RandSPD(n int, cond float64) [][]float64 {
+	if !(cond >= 1.0) || math.IsInf(cond, 1) {
+		s := "In matf64.%s the condition number must be finite and at least 1, but %v was passed."
+		panic(fmt.Sprintf(s, "RandSPD()", cond))
+	}
+	q := r.RandOrthogonal(n)
+	d := make([]float64, n)
+	for i := range d {
+		d[i] = 1.0
+		if n > 1 {
+			d[i] = math.Pow(cond, -float64(i)/float64(n-1))
+		}
+	}
+	m := New(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := 0.0
+			for k := 0; k < n; k++ {
+				sum += q[i][k] * d[k] * q[j][k]
+			}
+			m[i][j] = sum
+			m[j][i] = sum
+		}
+	}
+	return m
+}
+
+/*
+RandDiagDominant creates a random n by n strictly diagonally dominant
+[][]float64 drawn from r. The off diagonal entries are in [-1, 1), and each
+diagonal entry is positive and exceeds the sum of the magnitudes of the rest
+of its row by a random amount in [1, 2). Such [][]float64s are non-singular,
+and can be solved without pivoting and by iterative methods such as Jacobi.
+*/
+func (r *Rand) RandDiagDominant(n int) [][]float64 {
+	if debug {
+		check(checkDims("RandDiagDominant()", n))
+	}
+	m := New(n, n)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = 2.0*r.float64() - 1.0
+		}
+	}
+	for i := range m {
+		sum := 0.0
+		for j := range m[i] {
+			if j != i {
+				sum += math.Abs(m[i][j])
+			}
+		}
+		m[i][i] = sum + 1.0 + r.float64()
+	}
+	return m
+}
+
+/*
+RandLowRank creates a random x by y [][]float64 of the passed rank, drawn from
+r, as the product of a x by rank and a rank by y [][]float64 of standard
+normal entries. It panics if the rank is negative, or larger than x or y.
+*/
+func (r *Rand) RandLowRank(x, y, rank int) [][]float64 {
+	if rank < 0 || rank > x || rank > y {
+		s := "In matf64.%s the rank must be between 0 and %d for a %d by %d [][]float64,\n"
+		s += "but %d was passed."
+		k := x
+		if y < k {
+			k = y
+		}
+		panic(fmt.Sprintf(s, "RandLowRank()", k, x, y, rank))
+	}
+	if rank == 0 {
+		return New(x, y)
+	}
+	r.mu.Lock()
+	a, b := r.normals(x, rank), r.normals(rank, y)
+	r.mu.Unlock()
+	return Dot(a, b)
+}
+
+/*
+normals creates a x by y [][]float64 of standard normal entries. The caller
+must hold r.mu, so that all the entries are consecutive values of r.
+*/
+func (r *Rand) normals(x, y int) [][]float64 {
+	m := New(x, y)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = r.normFloat64()
+		}
+	}
+	return m
+}
+
+/*
+RandBanded creates a random x by y [][]float64 drawn from r, whose non-zero
+entries lie within lower diagonals below the main diagonal, and upper
+diagonals above it. That is, the entry at row i and column j is zero unless
+-lower <= j - i <= upper. For example, a random tridiagonal [][]float64 is
+given by:
+
+	m := r.RandBanded(n, n, 1, 1)
+
+The entries in the band are in [0, 1), unless another range is passed as for
+RandMat. It panics if lower or upper is negative.
+*/
+func (r *Rand) RandBanded(x, y, lower, upper int, args ...float64) [][]float64 {
+	if lower < 0 || upper < 0 {
+		s := "In matf64.%s the bandwidths must not be negative, but %d and %d were passed."
+		panic(fmt.Sprintf(s, "RandBanded()", lower, upper))
+	}
+	if debug {
+		check(checkDims("RandBanded()", x, y))
+	}
+	from, to := randRange("RandBanded()", args)
+	m := New(x, y)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range m {
+		for j := i - lower; j <= i+upper; j++ {
+			if j >= 0 && j < y {
+				m[i][j] = r.float64()*(to-from) + from
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,364 @@
+package matf64
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+/*
+ErrNoConvergence is returned by the iterative decompositions, such as SVD, when
+they fail to converge within their iteration limit.
+*/
+var ErrNoConvergence = errors.New("matf64: decomposition did not converge")
+
+// maxJacobiSweeps is the number of sweeps after which a Jacobi iteration gives up.
+const maxJacobiSweeps = 100
+
+/*
+SVD computes the full singular value decomposition of a m by n [][]float64 using
+the one-sided Jacobi method. u is a m by m orthogonal [][]float64, v is a n by n
+orthogonal [][]float64, and s holds the min(m, n) singular values in descending
+order, such that
+
+	m = Dot(Dot(u, S), T(v))
+
+where S is the m by n [][]float64 with s along its diagonal. ErrNoConvergence is
+returned if the Jacobi sweeps fail to converge. The passed [][]float64 is assumed
+to be non-jagged, and is not mutated in this function.
+*/
+func SVD(m [][]float64) (u [][]float64, s []float64, v [][]float64, err error) {
+	if debug {
+		check(checkRect("SVD()", m))
+	}
+	return svd(m, true)
+}
+
+/*
+ThinSVD computes the thin singular value decomposition of a m by n [][]float64.
+With k = min(m, n), u is a m by k [][]float64 and v is a n by k [][]float64,
+both with orthonormal columns, and s holds the k singular values in descending
+order, such that
+
+	m = Dot(Dot(u, S), T(v))
+
+where S is the k by k [][]float64 with s along its diagonal. ThinSVD otherwise
+behaves as SVD, and is the cheaper choice when the extra columns of the full
+decomposition are not needed.
+*/
+func ThinSVD(m [][]float64) (u [][]float64, s []float64, v [][]float64, err error) {
+	if debug {
+		check(checkRect("ThinSVD()", m))
+	}
+	return svd(m, false)
+}
+
+/*
+PInv returns the Moore-Penrose pseudo-inverse of a m by n [][]float64, which is
+a n by m [][]float64. Singular values less than or equal to a tolerance are
+treated as zero. By default the tolerance is max(m, n) times the largest
+singular value times the machine epsilon, but it can be set explicitly by
+passing an additional float64:
+
+	p, err := matf64.PInv(m, 1e-10)
+
+The original [][]float64 is not mutated in this function.
+*/
+func PInv(m [][]float64, tol ...float64) ([][]float64, error) {
+	u, s, v, err := ThinSVD(m)
+	if err != nil {
+		return nil, err
+	}
+	t := svdTol("PInv()", m, s, tol)
+	p := New(len(v), len(u))
+	for k := range s {
+		if s[k] <= t {
+			continue
+		}
+		for i := range p {
+			f := v[i][k] / s[k]
+			for j := range p[i] {
+				p[i][j] += f * u[j][k]
+			}
+		}
+	}
+	return p, nil
+}
+
+/*
+Rank returns the numerical rank of a [][]float64, which is the number of its
+singular values that are greater than a tolerance. The tolerance defaults to
+the one used by PInv, and can be set explicitly by passing an additional float64:
+
+	r, err := matf64.Rank(m, 1e-10)
+
+The original [][]float64 is not mutated in this function.
+*/
+func Rank(m [][]float64, tol ...float64) (int, error) {
+	_, s, _, err := ThinSVD(m)
+	if err != nil {
+		return 0, err
+	}
+	t := svdTol("Rank()", m, s, tol)
+	r := 0
+	for r < len(s) && s[r] > t {
+		r++
+	}
+	return r, nil
+}
+
+/*
+Cond returns the 2-norm condition number of a [][]float64, which is the ratio
+of its largest to its smallest singular value. The condition number of a
+singular [][]float64 is +Inf. The original [][]float64 is not mutated in this
+function.
+*/
+func Cond(m [][]float64) (float64, error) {
+	_, s, _, err := ThinSVD(m)
+	if err != nil {
+		return 0.0, err
+	}
+	if len(s) == 0 || s[len(s)-1] == 0.0 {
+		return math.Inf(1), nil
+	}
+	return s[0] / s[len(s)-1], nil
+}
+
+/*
+NullSpace returns an orthonormal basis for the null space of a m by n
+[][]float64, as the columns of a n by (n - r) [][]float64, where r is the rank
+of m. The tolerance for the rank determination follows Rank, and can be set
+by passing an additional float64. The original [][]float64 is not mutated in
+this function.
+*/
+func NullSpace(m [][]float64, tol ...float64) ([][]float64, error) {
+	_, s, v, err := SVD(m)
+	if err != nil {
+		return nil, err
+	}
+	t := svdTol("NullSpace()", m, s, tol)
+	r := 0
+	for r < len(s) && s[r] > t {
+		r++
+	}
+	ns := New(len(v), len(v)-r)
+	for i := range ns {
+		copy(ns[i], v[i][r:])
+	}
+	return ns, nil
+}
+
+/*
+svdTol returns the singular value tolerance for the calling function, either
+taken from the optional arguments of the caller, or derived from the shape of
+m and its largest singular value.
+*/
+func svdTol(caller string, m [][]float64, s []float64, tol []float64) float64 {
+	switch len(tol) {
+	case 0:
+		if len(s) == 0 {
+			return 0.0
+		}
+		d := len(m)
+		if len(m[0]) > d {
+			d = len(m[0])
+		}
+		return float64(d) * s[0] * eps
+	case 1:
+		return tol[0]
+	default:
+		s := "In matf64.%s expected 0 or 1 float64s for the tolerance, but recieved %d"
+		s = fmt.Sprintf(s, caller, len(tol))
+		panic(s)
+	}
+}
+
+/*
+svd computes the thin or full singular value decomposition of m, transposing
+wide input so that the Jacobi iteration always runs on a tall [][]float64.
+*/
+func svd(m [][]float64, full bool) (u [][]float64, s []float64, v [][]float64, err error) {
+	rows, cols := len(m), 0
+	if rows == 0 {
+		return [][]float64{}, []float64{}, [][]float64{}, nil
+	}
+	cols = len(m[0])
+	wide := cols > rows
+	a := m
+	if wide {
+		a = T(m)
+		rows, cols = cols, rows
+	}
+	uc, s, vc, err := jacobiSVD(a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	size := cols
+	if full {
+		size = rows
+	}
+	uc = completeBasis(uc, rows, size)
+	u, v = New(rows, len(uc)), New(cols, len(vc))
+	for j := range uc {
+		for i := range uc[j] {
+			u[i][j] = uc[j][i]
+		}
+	}
+	for j := range vc {
+		for i := range vc[j] {
+			v[i][j] = vc[j][i]
+		}
+	}
+	if wide {
+		u, v = v, u
+	}
+	return u, s, v, nil
+}
+
+/*
+jacobiSVD computes the thin singular value decomposition of a tall
+[][]float64 with the one-sided Jacobi method. The left and right singular
+vectors are returned as slices of columns, sorted by descending singular
+value. Left singular vectors belonging to a singular value at the rounding
+noise level are all zero, as their direction is meaningless.
+*/
+func jacobiSVD(a [][]float64) (u [][]float64, s []float64, v [][]float64, err error) {
+	w := T(a)
+	n := len(w)
+	vt := I(n)
+	// Columns whose squared norm falls below tiny hold nothing but rounding
+	// noise, and rotating them against each other never settles.
+	tiny := 0.0
+	for j := range w {
+		for i := range w[j] {
+			tiny += w[j][i] * w[j][i]
+		}
+	}
+	tiny *= eps * eps
+	converged := false
+	for sweep := 0; sweep < maxJacobiSweeps && !converged; sweep++ {
+		converged = true
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				alpha, beta, gamma := 0.0, 0.0, 0.0
+				for i := range w[p] {
+					alpha += w[p][i] * w[p][i]
+					beta += w[q][i] * w[q][i]
+					gamma += w[p][i] * w[q][i]
+				}
+				if alpha <= tiny || beta <= tiny || math.Abs(gamma) <= eps*math.Sqrt(alpha*beta) {
+					continue
+				}
+				converged = false
+				zeta := (beta - alpha) / (2.0 * gamma)
+				t := math.Copysign(1.0, zeta) / (math.Abs(zeta) + math.Sqrt(1.0+zeta*zeta))
+				c := 1.0 / math.Sqrt(1.0+t*t)
+				sn := c * t
+				rotate(w[p], w[q], c, sn)
+				rotate(vt[p], vt[q], c, sn)
+			}
+		}
+	}
+	if !converged {
+		return nil, nil, nil, ErrNoConvergence
+	}
+	s = make([]float64, n)
+	for j := range w {
+		for i := range w[j] {
+			s[j] = math.Hypot(s[j], w[j][i])
+		}
+		for i := range w[j] {
+			if s[j]*s[j] > tiny {
+				w[j][i] /= s[j]
+			} else {
+				w[j][i] = 0.0
+			}
+		}
+	}
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return s[idx[i]] > s[idx[j]] })
+	u, v = make([][]float64, n), make([][]float64, n)
+	sorted := make([]float64, n)
+	for i, k := range idx {
+		u[i], v[i], sorted[i] = w[k], vt[k], s[k]
+	}
+	return u, sorted, v, nil
+}
+
+/*
+rotate applies the plane rotation given by c and s to the pair of vectors x and
+y, modifying them in place.
+*/
+func rotate(x, y []float64, c, s float64) {
+	for i := range x {
+		xi, yi := x[i], y[i]
+		x[i] = c*xi - s*yi
+		y[i] = s*xi + c*yi
+	}
+}
+
+/*
+completeBasis takes a slice of orthonormal columns of length dim, where the
+all zero columns are placeholders, and returns size orthonormal columns by
+replacing the placeholders and appending new columns as needed. New columns are
+built by orthogonalizing the standard basis vectors against the existing ones.
+*/
+func completeBasis(cols [][]float64, dim, size int) [][]float64 {
+	valid := make([]bool, len(cols))
+	var basis [][]float64
+	for j := range cols {
+		for i := range cols[j] {
+			if cols[j][i] != 0.0 {
+				valid[j] = true
+				break
+			}
+		}
+		if valid[j] {
+			basis = append(basis, cols[j])
+		}
+	}
+	next := func() []float64 {
+		var best []float64
+		bestNorm := -1.0
+		for k := 0; k < dim; k++ {
+			e := make([]float64, dim)
+			e[k] = 1.0
+			for pass := 0; pass < 2; pass++ {
+				for _, b := range basis {
+					d := 0.0
+					for i := range e {
+						d += b[i] * e[i]
+					}
+					for i := range e {
+						e[i] -= d * b[i]
+					}
+				}
+			}
+			norm := 0.0
+			for i := range e {
+				norm = math.Hypot(norm, e[i])
+			}
+			if norm > bestNorm {
+				best, bestNorm = e, norm
+			}
+		}
+		for i := range best {
+			best[i] /= bestNorm
+		}
+		basis = append(basis, best)
+		return best
+	}
+	out := make([][]float64, size)
+	for j := range out {
+		if j < len(cols) && valid[j] {
+			out[j] = cols[j]
+		} else {
+			out[j] = next()
+		}
+	}
+	return out
+}
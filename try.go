@@ -0,0 +1,441 @@
+package matf64
+
+import "fmt"
+
+/*
+TryNew is the error returning variant of New. It returns an ArgError if it is
+not passed 1 or 2 dimensions, or if any of them are negative.
+*/
+func TryNew(dims ...int) ([][]float64, error) {
+	if len(dims) != 1 && len(dims) != 2 {
+		s := "expected 1 or 2 arguments, but received %d"
+		return nil, &ArgError{Op: "New()", Msg: fmt.Sprintf(s, len(dims))}
+	}
+	if err := checkDims("New()", dims...); err != nil {
+		return nil, err
+	}
+	return New(dims...), nil
+}
+
+/*
+TryI is the error returning variant of I. It returns an ArgError if the size
+is negative.
+*/
+func TryI(x int) ([][]float64, error) {
+	if err := checkDims("I()", x); err != nil {
+		return nil, err
+	}
+	return I(x), nil
+}
+
+/*
+TryRandMat is the error returning variant of RandMat. It returns an ArgError if
+either dimension is negative, or if more than 2 float64s are passed for the range.
+*/
+func TryRandMat(x, y int, args ...float64) ([][]float64, error) {
+	if err := checkDims("RandMat()", x, y); err != nil {
+		return nil, err
+	}
+	if err := checkRange("RandMat()", args); err != nil {
+		return nil, err
+	}
+	return RandMat(x, y, args...), nil
+}
+
+/*
+TryRandVec is the error returning variant of RandVec. It returns an ArgError if
+the size is negative, or if more than 2 float64s are passed for the range.
+*/
+func TryRandVec(size int, args ...float64) ([]float64, error) {
+	if err := checkDims("RandVec()", size); err != nil {
+		return nil, err
+	}
+	if err := checkRange("RandVec()", args); err != nil {
+		return nil, err
+	}
+	return RandVec(size, args...), nil
+}
+
+/*
+TryCol is the error returning variant of Col. It returns a JaggedError if m is
+jagged, and an IndexError if the column does not exist.
+*/
+func TryCol(m [][]float64, x int) ([]float64, error) {
+	if err := checkIndex("Col()", m, 1, x); err != nil {
+		return nil, err
+	}
+	return Col(m, x), nil
+}
+
+/*
+TryRow is the error returning variant of Row. It returns a JaggedError if m is
+jagged, and an IndexError if the row does not exist.
+*/
+func TryRow(m [][]float64, x int) ([]float64, error) {
+	if err := checkIndex("Row()", m, 0, x); err != nil {
+		return nil, err
+	}
+	return Row(m, x), nil
+}
+
+/*
+TryT is the error returning variant of T. It returns a JaggedError if m is
+jagged, and a ShapeError if m has no rows.
+*/
+func TryT(m [][]float64) ([][]float64, error) {
+	rows, _, err := shape("T()", m)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, &ShapeError{Op: "T()", WantRows: -1, WantCols: -1}
+	}
+	return T(m), nil
+}
+
+/*
+TryDot is the error returning variant of Dot. It returns a JaggedError if
+either [][]float64 is jagged, and a ShapeError describing n if its number of
+rows is not the number of columns of m.
+*/
+func TryDot(m, n [][]float64) ([][]float64, error) {
+	if err := checkDot("Dot()", m, n); err != nil {
+		return nil, err
+	}
+	return Dot(m, n), nil
+}
+
+/*
+TryAppendCol is the error returning variant of AppendCol. It returns a
+JaggedError if m is jagged, and a ShapeError if v does not have an entry for
+each row of m.
+*/
+func TryAppendCol(m [][]float64, v []float64) error {
+	if err := checkRect("AppendCol()", m); err != nil {
+		return err
+	}
+	if err := checkLen("AppendCol()", v, len(m)); err != nil {
+		return err
+	}
+	AppendCol(m, v)
+	return nil
+}
+
+/*
+TrySum is the error returning variant of Sum. It returns a JaggedError if m is
+jagged, an ArgError if the optional arguments are malformed, and an IndexError
+if the selected row or column does not exist.
+*/
+func TrySum(m [][]float64, args ...int) (float64, error) {
+	if err := checkAxisArgs("Sum()", m, args); err != nil {
+		return 0.0, err
+	}
+	return Sum(m, args...), nil
+}
+
+/*
+TryProd is the error returning variant of Prod, reporting errors as TrySum does.
+*/
+func TryProd(m [][]float64, args ...int) (float64, error) {
+	if err := checkAxisArgs("Prod()", m, args); err != nil {
+		return 0.0, err
+	}
+	return Prod(m, args...), nil
+}
+
+/*
+TryAvg is the error returning variant of Avg, reporting errors as TrySum does.
+*/
+func TryAvg(m [][]float64, args ...int) (float64, error) {
+	if err := checkAxisArgs("Avg()", m, args); err != nil {
+		return 0.0, err
+	}
+	return Avg(m, args...), nil
+}
+
+/*
+TryMult is the error returning variant of Mult. It returns an ArgError if val
+is not a float64, []float64 or [][]float64, a JaggedError if either
+[][]float64 is jagged, and a ShapeError if the shape of val does not match m.
+m is only modified if no error is returned.
+*/
+func TryMult(m [][]float64, val interface{}) error {
+	if err := checkOperand("Mult()", m, val); err != nil {
+		return err
+	}
+	Mult(m, val)
+	return nil
+}
+
+/*
+TryAdd is the error returning variant of Add, reporting errors as TryMult does.
+*/
+func TryAdd(m [][]float64, val interface{}) error {
+	if err := checkOperand("Add()", m, val); err != nil {
+		return err
+	}
+	Add(m, val)
+	return nil
+}
+
+/*
+TrySub is the error returning variant of Sub, reporting errors as TryMult does.
+*/
+func TrySub(m [][]float64, val interface{}) error {
+	if err := checkOperand("Sub()", m, val); err != nil {
+		return err
+	}
+	Sub(m, val)
+	return nil
+}
+
+/*
+TryDiv is the error returning variant of Div, reporting errors as TryMult does.
+*/
+func TryDiv(m [][]float64, val interface{}) error {
+	if err := checkOperand("Div()", m, val); err != nil {
+		return err
+	}
+	Div(m, val)
+	return nil
+}
+
+/*
+TryApply is the error returning variant of Apply. It returns an ArgError if m
+is not a []float64 or [][]float64.
+*/
+func TryApply(m interface{}, f TransformerFn) error {
+	switch v := m.(type) {
+	case []float64, [][]float64:
+		Apply(v, f)
+		return nil
+	default:
+		s := "expected []float64, or [][]float64 but received type: %T"
+		return &ArgError{Op: "Apply()", Msg: fmt.Sprintf(s, v)}
+	}
+}
+
+/*
+TrySet is the error returning variant of Set. It returns an ArgError if m is
+not a []float64 or [][]float64.
+*/
+func TrySet(m interface{}, val float64) error {
+	switch v := m.(type) {
+	case []float64, [][]float64:
+		Set(v, val)
+		return nil
+	default:
+		s := "expected []float64, or [][]float64 but received type: %T"
+		return &ArgError{Op: "Set()", Msg: fmt.Sprintf(s, v)}
+	}
+}
+
+/*
+TryLU is the error returning variant of LU. It returns a JaggedError if m is
+jagged, and a ShapeError if it is not square, in addition to the errors of LU.
+*/
+func TryLU(m [][]float64) (l, u [][]float64, p []int, err error) {
+	if err := checkSquareShape("LU()", m); err != nil {
+		return nil, nil, nil, err
+	}
+	return LU(m)
+}
+
+/*
+TrySolve is the error returning variant of Solve. It returns a JaggedError if a
+is jagged, and a ShapeError if a is not square or b does not have an entry for
+each row of a, in addition to the errors of Solve.
+*/
+func TrySolve(a [][]float64, b []float64) ([]float64, error) {
+	if err := checkSquareShape("Solve()", a); err != nil {
+		return nil, err
+	}
+	if err := checkLen("Solve()", b, len(a)); err != nil {
+		return nil, err
+	}
+	return Solve(a, b)
+}
+
+/*
+TryInverse is the error returning variant of Inverse, reporting shape errors
+as TryLU does.
+*/
+func TryInverse(m [][]float64) ([][]float64, error) {
+	if err := checkSquareShape("Inverse()", m); err != nil {
+		return nil, err
+	}
+	return Inverse(m)
+}
+
+/*
+TryDet is the error returning variant of Det, reporting errors as TryLU does.
+*/
+func TryDet(m [][]float64) (float64, error) {
+	if err := checkSquareShape("Det()", m); err != nil {
+		return 0.0, err
+	}
+	return Det(m), nil
+}
+
+/*
+TryCholesky is the error returning variant of Cholesky, reporting shape errors
+as TryLU does.
+*/
+func TryCholesky(m [][]float64) ([][]float64, error) {
+	if err := checkSquareShape("Cholesky()", m); err != nil {
+		return nil, err
+	}
+	return Cholesky(m)
+}
+
+/*
+TryCholeskySolve is the error returning variant of CholeskySolve. It returns a
+JaggedError if l is jagged, and a ShapeError if l is not square or b does not
+have an entry for each row of l.
+*/
+func TryCholeskySolve(l [][]float64, b []float64) ([]float64, error) {
+	if err := checkSquareShape("CholeskySolve()", l); err != nil {
+		return nil, err
+	}
+	if err := checkLen("CholeskySolve()", b, len(l)); err != nil {
+		return nil, err
+	}
+	return CholeskySolve(l, b), nil
+}
+
+/*
+TryEigSym is the error returning variant of EigSym, reporting shape errors as
+TryLU does.
+*/
+func TryEigSym(m [][]float64) (vals []float64, vecs [][]float64, err error) {
+	if err := checkSquareShape("EigSym()", m); err != nil {
+		return nil, nil, err
+	}
+	return EigSym(m)
+}
+
+/*
+TryEig is the error returning variant of Eig, reporting shape errors as TryLU
+does.
+*/
+func TryEig(m [][]float64) (vals []complex128, vecs [][]complex128, err error) {
+	if err := checkSquareShape("Eig()", m); err != nil {
+		return nil, nil, err
+	}
+	return Eig(m)
+}
+
+/*
+TryEigVals is the error returning variant of EigVals, reporting shape errors as
+TryLU does.
+*/
+func TryEigVals(m [][]float64) ([]complex128, error) {
+	if err := checkSquareShape("EigVals()", m); err != nil {
+		return nil, err
+	}
+	return EigVals(m)
+}
+
+/*
+TryLeastSquares is the error returning variant of LeastSquares. It returns a
+JaggedError if a is jagged, and a ShapeError if b does not have an entry for
+each row of a.
+*/
+func TryLeastSquares(a [][]float64, b []float64) (x, res []float64, rank int, err error) {
+	if err := checkRect("LeastSquares()", a); err != nil {
+		return nil, nil, 0, err
+	}
+	if err := checkLen("LeastSquares()", b, len(a)); err != nil {
+		return nil, nil, 0, err
+	}
+	x, res, rank = LeastSquares(a, b)
+	return x, res, rank, nil
+}
+
+/*
+TryQR is the error returning variant of QR. It returns a JaggedError if m is
+jagged.
+*/
+func TryQR(m [][]float64) (q, r [][]float64, err error) {
+	if err := checkRect("QR()", m); err != nil {
+		return nil, nil, err
+	}
+	q, r = QR(m)
+	return q, r, nil
+}
+
+/*
+TrySVD is the error returning variant of SVD. It returns a JaggedError if m is
+jagged, and otherwise whatever SVD returns.
+*/
+func TrySVD(m [][]float64) (u [][]float64, s []float64, v [][]float64, err error) {
+	if err := checkRect("SVD()", m); err != nil {
+		return nil, nil, nil, err
+	}
+	return SVD(m)
+}
+
+/*
+TryThinSVD is the error returning variant of ThinSVD. It returns a JaggedError
+if m is jagged, and otherwise whatever ThinSVD returns.
+*/
+func TryThinSVD(m [][]float64) (u [][]float64, s []float64, v [][]float64, err error) {
+	if err := checkRect("ThinSVD()", m); err != nil {
+		return nil, nil, nil, err
+	}
+	return ThinSVD(m)
+}
+
+/*
+TryPInv is the error returning variant of PInv. It returns a JaggedError if m is
+jagged, and an ArgError if more than one tolerance is passed.
+*/
+func TryPInv(m [][]float64, tol ...float64) ([][]float64, error) {
+	if err := checkRect("PInv()", m); err != nil {
+		return nil, err
+	}
+	if err := checkTol("PInv()", tol); err != nil {
+		return nil, err
+	}
+	return PInv(m, tol...)
+}
+
+/*
+TryRank is the error returning variant of Rank. It returns a JaggedError if m is
+jagged, and an ArgError if more than one tolerance is passed.
+*/
+func TryRank(m [][]float64, tol ...float64) (int, error) {
+	if err := checkRect("Rank()", m); err != nil {
+		return 0, err
+	}
+	if err := checkTol("Rank()", tol); err != nil {
+		return 0, err
+	}
+	return Rank(m, tol...)
+}
+
+/*
+TryCond is the error returning variant of Cond. It returns a JaggedError if m is
+jagged.
+*/
+func TryCond(m [][]float64) (float64, error) {
+	if err := checkRect("Cond()", m); err != nil {
+		return 0.0, err
+	}
+	return Cond(m)
+}
+
+/*
+TryNullSpace is the error returning variant of NullSpace. It returns a
+JaggedError if m is jagged, and an ArgError if more than one tolerance is
+passed.
+*/
+func TryNullSpace(m [][]float64, tol ...float64) ([][]float64, error) {
+	if err := checkRect("NullSpace()", m); err != nil {
+		return nil, err
+	}
+	if err := checkTol("NullSpace()", tol); err != nil {
+		return nil, err
+	}
+	return NullSpace(m, tol...)
+}